@@ -0,0 +1,57 @@
+package currency
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// MemoryProvider guarda las tasas en memoria y las reemplaza con SetRates; se
+// pierden al reiniciar. Empieza solo con la moneda base.
+type MemoryProvider struct {
+	mutex sync.RWMutex
+	rates *Rates
+	table table
+}
+
+func NewMemoryProvider(base string) *MemoryProvider {
+	rates := &Rates{Base: models.NormalizeCurrency(base), Rates: map[string]string{}, Updated_at: time.Now().UTC()}
+	parsed, _ := newTable(rates)
+	return &MemoryProvider{rates: rates, table: parsed}
+}
+
+func (p *MemoryProvider) Rate(ctx context.Context, from string, to string) (*big.Rat, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.table.rate(from, to)
+}
+
+func (p *MemoryProvider) Rates(ctx context.Context) (*Rates, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return copyRates(p.rates), nil
+}
+
+// SetRates valida todas las tasas antes de reemplazar las anteriores.
+func (p *MemoryProvider) SetRates(ctx context.Context, rates *Rates) error {
+	parsed, err := newTable(rates)
+	if err != nil {
+		return err
+	}
+	stored := &Rates{Base: models.NormalizeCurrency(rates.Base), Rates: map[string]string{}, Updated_at: time.Now().UTC()}
+	for code, rate := range rates.Rates {
+		stored.Rates[models.NormalizeCurrency(code)] = strings.TrimSpace(rate)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.rates = stored
+	p.table = parsed
+	return nil
+}
@@ -0,0 +1,88 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unsupported currency")
+	ErrReadOnly        = errors.New("exchange rates cannot be updated")
+)
+
+// Rates guarda cuántas unidades de cada moneda vale una unidad de Base. Las
+// tasas son texto decimal para no perder precisión.
+type Rates struct {
+	Base       string            `json:"base"`
+	Rates      map[string]string `json:"rates"`
+	Updated_at time.Time         `json:"updated_at"`
+}
+
+// RateProvider entrega tasas de cambio. Rate devuelve ErrUnknownCurrency si
+// no conoce alguna de las dos monedas.
+type RateProvider interface {
+	Rate(ctx context.Context, from string, to string) (*big.Rat, error)
+	Rates(ctx context.Context) (*Rates, error)
+}
+
+// Updater lo implementan los proveedores cuyas tasas se cambian por la API.
+type Updater interface {
+	SetRates(ctx context.Context, rates *Rates) error
+}
+
+// Convert pasa el precio a la moneda pedida y lo redondea a sus decimales.
+func Convert(ctx context.Context, provider RateProvider, price models.Money, to string) (models.Money, error) {
+	to = models.NormalizeCurrency(to)
+	if models.NormalizeCurrency(price.Currency) == to {
+		return models.NewMoney(price.Amount, to), nil
+	}
+	rate, err := provider.Rate(ctx, price.Currency, to)
+	if err != nil {
+		return models.Money{}, err
+	}
+	return price.Convert(to, rate), nil
+}
+
+// table es la forma ya validada de Rates, con la base incluida a 1.
+type table map[string]*big.Rat
+
+func newTable(rates *Rates) (table, error) {
+	base := models.NormalizeCurrency(rates.Base)
+	if !models.ValidCurrency(base) {
+		return nil, fmt.Errorf("invalid base currency %q", rates.Base)
+	}
+	parsed := table{base: big.NewRat(1, 1)}
+	for code, value := range rates.Rates {
+		code = models.NormalizeCurrency(code)
+		if !models.ValidCurrency(code) {
+			return nil, fmt.Errorf("invalid currency %q", code)
+		}
+		rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("rate for %s must be a positive decimal", code)
+		}
+		if code == base && rate.Cmp(big.NewRat(1, 1)) != 0 {
+			return nil, fmt.Errorf("rate for the base currency must be 1")
+		}
+		parsed[code] = rate
+	}
+	return parsed, nil
+}
+
+func (t table) rate(from string, to string) (*big.Rat, error) {
+	fromRate, ok := t[models.NormalizeCurrency(from)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, models.NormalizeCurrency(from))
+	}
+	toRate, ok := t[models.NormalizeCurrency(to)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, models.NormalizeCurrency(to))
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
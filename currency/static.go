@@ -0,0 +1,53 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+)
+
+// StaticProvider lee las tasas de un archivo JSON con la forma de Rates al
+// arrancar y no las cambia después.
+type StaticProvider struct {
+	rates *Rates
+	table table
+}
+
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rates Rates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, err
+	}
+	parsed, err := newTable(&rates)
+	if err != nil {
+		return nil, err
+	}
+	if rates.Updated_at.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			rates.Updated_at = info.ModTime().UTC()
+		}
+	}
+	return &StaticProvider{rates: &rates, table: parsed}, nil
+}
+
+func (p *StaticProvider) Rate(ctx context.Context, from string, to string) (*big.Rat, error) {
+	return p.table.rate(from, to)
+}
+
+func (p *StaticProvider) Rates(ctx context.Context) (*Rates, error) {
+	return copyRates(p.rates), nil
+}
+
+func copyRates(rates *Rates) *Rates {
+	copied := *rates
+	copied.Rates = make(map[string]string, len(rates.Rates))
+	for code, rate := range rates.Rates {
+		copied.Rates[code] = rate
+	}
+	return &copied
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// productSortColumns es la lista blanca de columnas por las que se puede
+// ordenar; nunca se concatena al SQL un valor que venga del cliente.
+var productSortColumns = map[string]string{
+	models.SortByCreatedAt: "created_at",
+	models.SortByPrice:     "price",
+	models.SortByTitle:     "title",
+}
+
+// buildProductQuery pagina por keyset sobre (columna de orden, id), así las
+// inserciones concurrentes no hacen saltar ni repetir filas como con OFFSET.
+func buildProductQuery(filter models.ProductFilter) (string, []any) {
+	query, args := buildProductSelect(filter)
+
+	// Se pide un producto de más para saber si hay otra página.
+	args = append(args, filter.Limit+1)
+	query += fmt.Sprintf(" LIMIT $%d", len(args))
+	return query, args
+}
+
+// buildProductSelect arma el SELECT filtrado y ordenado, sin límite.
+func buildProductSelect(filter models.ProductFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	where := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Currency != "" {
+		where("currency = $%d", filter.Currency)
+	}
+	if filter.MinPrice != nil {
+		where("price >= $%d", filter.MinPrice.FloatString(models.MaxCurrencyExponent))
+	}
+	if filter.MaxPrice != nil {
+		where("price <= $%d", filter.MaxPrice.FloatString(models.MaxCurrencyExponent))
+	}
+	if filter.UserId != "" {
+		where("user_id = $%d", filter.UserId)
+	}
+	if filter.CategoryId != "" {
+		where(`id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE id = $%d
+				UNION ALL
+				SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT pc.product_id FROM product_categories pc JOIN tree ON tree.id = pc.category_id)`, filter.CategoryId)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		where("created_at > $%d", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		where("created_at < $%d", filter.CreatedBefore)
+	}
+
+	column, ok := productSortColumns[filter.SortBy]
+	if !ok {
+		column = productSortColumns[models.SortByCreatedAt]
+	}
+	direction, comparison := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.SQLValue(), filter.After.Id)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, comparison, len(args)-1, len(args)))
+	}
+
+	query := "SELECT id , title , description , image_url , currency , price , tax_class , weight_grams , length_mm , width_mm , height_mm ,created_at,updated_at,user_id FROM products"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
+	return query, args
+}
+
+// newProductPage recibe hasta Limit+1 productos; el sobrante solo indica que
+// hay otra página.
+func newProductPage(filter models.ProductFilter, products []*models.Products) *models.ProductPage {
+	page := &models.ProductPage{Items: products}
+	if len(products) > filter.Limit {
+		page.Items = products[:filter.Limit]
+		page.HasMore = true
+		page.NextCursor = models.NewProductCursor(filter, page.Items[len(page.Items)-1]).Encode()
+	}
+	return page
+}
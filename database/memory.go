@@ -0,0 +1,233 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// MemoryRepository guarda todo en mapas protegidos por un mutex. Sirve para
+// desarrollo local y para pruebas con httptest sin levantar Postgres.
+type MemoryRepository struct {
+	mutex             sync.RWMutex
+	users             map[string]*models.User
+	products          map[string]*models.Products
+	productIds        []string
+	carts             map[string]*memoryCart
+	orders            map[string]*models.Order
+	orderIds          []string
+	payments          []*models.Payment
+	refreshTokens     map[string]*models.RefreshToken
+	revokedTokens     map[string]time.Time
+	categories        map[string]*models.Category
+	productCategories map[string][]string
+	productOptions    map[string][]*models.ProductOption
+	variants          map[string]*models.ProductVariant
+	variantIds        []string
+	productImages     map[string][]*models.ProductImage
+	inventory         map[string]*models.Inventory
+	reservations      []*models.Reservation
+	reviews           []*models.Review
+	wishlists         map[string][]memoryWishlistItem
+	coupons           map[string]*models.Coupon
+	redemptions       []*models.CouponRedemption
+	taxRules          map[string]*models.TaxRule
+	shippingMethods   map[string]*models.ShippingMethod
+	addresses         map[string][]*models.Address
+	catalogDeletedAt  time.Time
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		users:             make(map[string]*models.User),
+		products:          make(map[string]*models.Products),
+		carts:             make(map[string]*memoryCart),
+		orders:            make(map[string]*models.Order),
+		refreshTokens:     make(map[string]*models.RefreshToken),
+		revokedTokens:     make(map[string]time.Time),
+		categories:        make(map[string]*models.Category),
+		productCategories: make(map[string][]string),
+		productOptions:    make(map[string][]*models.ProductOption),
+		variants:          make(map[string]*models.ProductVariant),
+		productImages:     make(map[string][]*models.ProductImage),
+		inventory:         make(map[string]*models.Inventory),
+		wishlists:         make(map[string][]memoryWishlistItem),
+		coupons:           make(map[string]*models.Coupon),
+		taxRules:          make(map[string]*models.TaxRule),
+		shippingMethods:   make(map[string]*models.ShippingMethod),
+		addresses:         make(map[string][]*models.Address),
+		catalogDeletedAt:  time.Now(),
+	}
+}
+
+func (repo *MemoryRepository) InsertUser(ctx context.Context, user *models.User) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored := *user
+	repo.users[user.Id] = &stored
+	return nil
+}
+
+func (repo *MemoryRepository) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &models.User{Id: stored.Id, Email: stored.Email, Role: stored.Role}, nil
+}
+
+func (repo *MemoryRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	for _, stored := range repo.users {
+		if stored.Email == email {
+			user := *stored
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *MemoryRepository) UpdateUserRole(ctx context.Context, id string, role models.Role) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if stored, ok := repo.users[id]; ok {
+		stored.Role = role
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) InsertProduct(ctx context.Context, product *models.Products) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored := *product
+	stored.Created_at = time.Now()
+	stored.Updated_at = stored.Created_at
+	repo.products[product.Id] = &stored
+	repo.productIds = append(repo.productIds, product.Id)
+	return nil
+}
+
+func (repo *MemoryRepository) GetProductById(ctx context.Context, id string) (*models.Products, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.products[id]
+	if !ok {
+		return nil, nil
+	}
+	product := *stored
+	return &product, nil
+}
+
+func (repo *MemoryRepository) UpdateProduct(ctx context.Context, product *models.Products) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.products[product.Id]
+	if !ok || stored.UserId != product.UserId {
+		return models.ErrProductNotFound
+	}
+	stored.Title = product.Title
+	stored.Description = product.Description
+	stored.ImageUrl = product.ImageUrl
+	stored.Price = product.Price
+	stored.Currency = product.Currency
+	stored.TaxClass = product.TaxClass
+	stored.Dimensions = product.Dimensions
+	stored.Updated_at = time.Now()
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteProduct(ctx context.Context, id string, userID string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.products[id]
+	if !ok || stored.UserId != userID {
+		return models.ErrProductNotFound
+	}
+	delete(repo.products, id)
+	delete(repo.productCategories, id)
+	repo.deleteProductVariants(id)
+	delete(repo.inventory, id)
+	delete(repo.productImages, id)
+	repo.catalogDeletedAt = time.Now()
+	repo.reviews = slices.DeleteFunc(repo.reviews, func(review *models.Review) bool { return review.ProductId == id })
+	repo.productIds = removeId(repo.productIds, id)
+	for _, cart := range repo.carts {
+		cart.removeMatching(func(key cartKey) bool { return key.productId == id })
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) ListProducts(ctx context.Context, filter models.ProductFilter) (*models.ProductPage, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var inCategory map[string]bool
+	if filter.CategoryId != "" {
+		inCategory = repo.categoryProductIds(filter.CategoryId)
+	}
+
+	var matching []*models.Products
+	for _, id := range repo.productIds {
+		product := repo.products[id]
+		if !filter.Matches(product) {
+			continue
+		}
+		if inCategory != nil && !inCategory[id] {
+			continue
+		}
+		if filter.After != nil && filter.After.Compare(product) <= 0 {
+			continue
+		}
+		matching = append(matching, product)
+	}
+	sortProducts(matching, filter)
+
+	products := []*models.Products{}
+	for i := 0; i <= filter.Limit && i < len(matching); i++ {
+		product := *matching[i]
+		products = append(products, &product)
+	}
+	return newProductPage(filter, products), nil
+}
+
+func sortProducts(products []*models.Products, filter models.ProductFilter) {
+	compare := func(a, b *models.Products) int {
+		switch filter.SortBy {
+		case models.SortByPrice:
+			return a.Price.Rat().Cmp(b.Price.Rat())
+		case models.SortByTitle:
+			return cmp.Compare(a.Title, b.Title)
+		default:
+			return a.Created_at.Compare(b.Created_at)
+		}
+	}
+	slices.SortStableFunc(products, func(a, b *models.Products) int {
+		order := compare(a, b)
+		if order == 0 {
+			order = cmp.Compare(a.Id, b.Id)
+		}
+		if filter.SortDesc {
+			return -order
+		}
+		return order
+	})
+}
+
+func (repo *MemoryRepository) Close() error {
+	return nil
+}
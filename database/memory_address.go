@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertAddress(ctx context.Context, address *models.Address) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	address.Created_at = time.Now()
+	address.Updated_at = address.Created_at
+	repo.clearDefaultAddresses(address)
+	stored := *address
+	repo.addresses[address.UserId] = append(repo.addresses[address.UserId], &stored)
+	return nil
+}
+
+func (repo *MemoryRepository) GetAddressById(ctx context.Context, userID string, id string) (*models.Address, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	for _, stored := range repo.addresses[userID] {
+		if stored.Id == id {
+			address := *stored
+			return &address, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *MemoryRepository) ListAddresses(ctx context.Context, userID string) ([]*models.Address, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	addresses := []*models.Address{}
+	for _, stored := range repo.addresses[userID] {
+		address := *stored
+		addresses = append(addresses, &address)
+	}
+	return addresses, nil
+}
+
+func (repo *MemoryRepository) UpdateAddress(ctx context.Context, address *models.Address) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	i := slices.IndexFunc(repo.addresses[address.UserId], func(stored *models.Address) bool { return stored.Id == address.Id })
+	if i < 0 {
+		return models.ErrAddressNotFound
+	}
+	repo.clearDefaultAddresses(address)
+	address.Created_at = repo.addresses[address.UserId][i].Created_at
+	address.Updated_at = time.Now()
+	stored := *address
+	repo.addresses[address.UserId][i] = &stored
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteAddress(ctx context.Context, userID string, id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	addresses := repo.addresses[userID]
+	i := slices.IndexFunc(addresses, func(stored *models.Address) bool { return stored.Id == id })
+	if i < 0 {
+		return models.ErrAddressNotFound
+	}
+	repo.addresses[userID] = slices.Delete(addresses, i, i+1)
+	return nil
+}
+
+// clearDefaultAddresses le quita la marca de dirección por defecto a las otras
+// direcciones del usuario. Se llama con el mutex tomado.
+func (repo *MemoryRepository) clearDefaultAddresses(address *models.Address) {
+	for _, stored := range repo.addresses[address.UserId] {
+		if stored.Id == address.Id {
+			continue
+		}
+		if address.DefaultShipping {
+			stored.DefaultShipping = false
+		}
+		if address.DefaultBilling {
+			stored.DefaultBilling = false
+		}
+	}
+}
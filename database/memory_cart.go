@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// cartKey identifica un item del carrito; VariantId vacío es el producto sin
+// variante.
+type cartKey struct {
+	productId string
+	variantId string
+}
+
+type memoryCart struct {
+	keys       []cartKey
+	quantities map[cartKey]int
+	couponCode string
+	updatedAt  time.Time
+}
+
+func (repo *MemoryRepository) GetCart(ctx context.Context, userID string) (*models.Cart, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	cart := &models.Cart{UserId: userID, Items: []*models.CartItem{}}
+	stored, ok := repo.carts[userID]
+	if !ok {
+		return cart, nil
+	}
+
+	cart.CouponCode = stored.couponCode
+	cart.Updated_at = stored.updatedAt
+	for _, key := range stored.keys {
+		product, ok := repo.products[key.productId]
+		if !ok {
+			continue
+		}
+		item := &models.CartItem{
+			ProductId:   key.productId,
+			Title:       product.Title,
+			Price:       product.Price,
+			Quantity:    stored.quantities[key],
+			TaxClass:    product.TaxClass,
+			WeightGrams: product.WeightGrams,
+		}
+		if key.variantId != "" {
+			variant, ok := repo.variants[key.variantId]
+			if !ok {
+				continue
+			}
+			item.VariantId = variant.Id
+			item.Sku = variant.Sku
+			item.Price = variant.EffectivePrice(product.Price)
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	return cart, nil
+}
+
+func (repo *MemoryRepository) AddCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.carts[userID]
+	if !ok {
+		stored = &memoryCart{quantities: make(map[cartKey]int)}
+		repo.carts[userID] = stored
+	}
+	key := cartKey{productID, variantID}
+	current, ok := stored.quantities[key]
+	if current+quantity > models.MaxCartItemQuantity {
+		return models.ErrCartItemQuantity
+	}
+	if !ok {
+		stored.keys = append(stored.keys, key)
+	}
+	stored.quantities[key] = current + quantity
+	stored.updatedAt = time.Now()
+	return nil
+}
+
+func (repo *MemoryRepository) UpdateCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.carts[userID]
+	if !ok {
+		return models.ErrCartItemNotFound
+	}
+	key := cartKey{productID, variantID}
+	if _, ok := stored.quantities[key]; !ok {
+		return models.ErrCartItemNotFound
+	}
+	stored.quantities[key] = quantity
+	stored.updatedAt = time.Now()
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteCartItem(ctx context.Context, userID string, productID string, variantID string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.carts[userID]
+	if !ok || !stored.remove(cartKey{productID, variantID}) {
+		return models.ErrCartItemNotFound
+	}
+	stored.updatedAt = time.Now()
+	return nil
+}
+
+func (cart *memoryCart) remove(key cartKey) bool {
+	if _, ok := cart.quantities[key]; !ok {
+		return false
+	}
+	delete(cart.quantities, key)
+	cart.keys = slices.DeleteFunc(cart.keys, func(current cartKey) bool { return current == key })
+	return true
+}
+
+// removeMatching quita los items que cumplen match, por ejemplo todos los de
+// un producto borrado.
+func (cart *memoryCart) removeMatching(match func(key cartKey) bool) {
+	for _, key := range slices.Clone(cart.keys) {
+		if match(key) {
+			cart.remove(key)
+		}
+	}
+}
+
+func removeId(ids []string, id string) []string {
+	for i, current := range ids {
+		if current == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertCategory(ctx context.Context, category *models.Category) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	category.Created_at = time.Now()
+	stored := *category
+	repo.categories[category.Id] = &stored
+	return nil
+}
+
+func (repo *MemoryRepository) GetCategoryById(ctx context.Context, id string) (*models.Category, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.categories[id]
+	if !ok {
+		return nil, nil
+	}
+	category := *stored
+	return &category, nil
+}
+
+func (repo *MemoryRepository) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	categories := []*models.Category{}
+	for _, stored := range repo.categories {
+		category := *stored
+		categories = append(categories, &category)
+	}
+	sortCategories(categories)
+	return categories, nil
+}
+
+func (repo *MemoryRepository) UpdateCategory(ctx context.Context, category *models.Category) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if stored, ok := repo.categories[category.Id]; ok {
+		stored.Name = category.Name
+		stored.ParentId = category.ParentId
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	for _, stored := range repo.categories {
+		if stored.ParentId == id {
+			return models.ErrCategoryHasChildren
+		}
+	}
+	for _, rule := range repo.taxRules {
+		if rule.CategoryId == id {
+			return models.ErrCategoryHasTaxRules
+		}
+	}
+	delete(repo.categories, id)
+	for productID, categoryIDs := range repo.productCategories {
+		repo.productCategories[productID] = removeId(categoryIDs, id)
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	unique := []string{}
+	for _, categoryID := range categoryIDs {
+		if !slices.Contains(unique, categoryID) {
+			unique = append(unique, categoryID)
+		}
+	}
+	repo.productCategories[productID] = unique
+	return nil
+}
+
+func (repo *MemoryRepository) ListProductCategories(ctx context.Context, productID string) ([]*models.Category, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	categories := []*models.Category{}
+	for _, categoryID := range repo.productCategories[productID] {
+		if stored, ok := repo.categories[categoryID]; ok {
+			category := *stored
+			categories = append(categories, &category)
+		}
+	}
+	sortCategories(categories)
+	return categories, nil
+}
+
+// categoryProductIds devuelve los productos de la categoría y de todas sus
+// subcategorías. Se llama con el mutex tomado.
+func (repo *MemoryRepository) categoryProductIds(categoryID string) map[string]bool {
+	categories := make([]*models.Category, 0, len(repo.categories))
+	for _, stored := range repo.categories {
+		categories = append(categories, stored)
+	}
+	tree := models.DescendantIds(categories, categoryID)
+
+	productIds := make(map[string]bool)
+	for productID, categoryIDs := range repo.productCategories {
+		for _, id := range categoryIDs {
+			if slices.Contains(tree, id) {
+				productIds[productID] = true
+				break
+			}
+		}
+	}
+	return productIds
+}
+
+func sortCategories(categories []*models.Category) {
+	slices.SortFunc(categories, func(a, b *models.Category) int {
+		if order := strings.Compare(a.Name, b.Name); order != 0 {
+			return order
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+}
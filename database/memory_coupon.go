@@ -0,0 +1,145 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertCoupon(ctx context.Context, coupon *models.Coupon) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.coupons[coupon.Code]; ok {
+		return models.ErrDuplicateCoupon
+	}
+	coupon.Created_at = time.Now()
+	repo.coupons[coupon.Code] = copyCoupon(coupon)
+	return nil
+}
+
+func (repo *MemoryRepository) GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.coupons[code]
+	if !ok {
+		return nil, nil
+	}
+	coupon := copyCoupon(stored)
+	coupon.Uses, _ = repo.couponUses(code, "")
+	return coupon, nil
+}
+
+func (repo *MemoryRepository) ListCoupons(ctx context.Context) ([]*models.Coupon, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	coupons := []*models.Coupon{}
+	for code, stored := range repo.coupons {
+		coupon := copyCoupon(stored)
+		coupon.Uses, _ = repo.couponUses(code, "")
+		coupons = append(coupons, coupon)
+	}
+	slices.SortFunc(coupons, func(a, b *models.Coupon) int {
+		if order := b.Created_at.Compare(a.Created_at); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.Code, b.Code)
+	})
+	return coupons, nil
+}
+
+func (repo *MemoryRepository) UpdateCoupon(ctx context.Context, coupon *models.Coupon) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.coupons[coupon.Code]
+	if !ok {
+		return nil
+	}
+	updated := copyCoupon(coupon)
+	updated.Created_at = stored.Created_at
+	repo.coupons[coupon.Code] = updated
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteCoupon(ctx context.Context, code string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.coupons[code]; !ok {
+		return models.ErrCouponNotFound
+	}
+	if slices.ContainsFunc(repo.redemptions, func(redemption *models.CouponRedemption) bool { return redemption.Code == code }) {
+		return models.ErrCouponInUse
+	}
+	delete(repo.coupons, code)
+	return nil
+}
+
+func (repo *MemoryRepository) CountCouponUses(ctx context.Context, code string, userID string) (int, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	_, userUses := repo.couponUses(code, userID)
+	return userUses, nil
+}
+
+func (repo *MemoryRepository) SetCartCoupon(ctx context.Context, userID string, code string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.carts[userID]
+	if !ok {
+		stored = &memoryCart{quantities: make(map[cartKey]int)}
+		repo.carts[userID] = stored
+	}
+	stored.couponCode = code
+	stored.updatedAt = time.Now()
+	return nil
+}
+
+// couponUses devuelve los canjes totales del cupón y los del usuario. Se llama
+// con el mutex tomado.
+func (repo *MemoryRepository) couponUses(code string, userID string) (int, int) {
+	uses, userUses := 0, 0
+	for _, redemption := range repo.redemptions {
+		if redemption.Code != code {
+			continue
+		}
+		uses++
+		if redemption.UserId == userID {
+			userUses++
+		}
+	}
+	return uses, userUses
+}
+
+// checkCoupon revisa el cupón de la orden antes de tocar el stock. Se llama
+// con el mutex tomado.
+func (repo *MemoryRepository) checkCoupon(order *models.Order, now time.Time) error {
+	coupon, ok := repo.coupons[order.CouponCode]
+	if !ok {
+		return fmt.Errorf("%w: coupon no longer exists", models.ErrInvalidCoupon)
+	}
+	uses, userUses := repo.couponUses(order.CouponCode, order.UserId)
+	return coupon.CheckAvailable(now, uses, userUses)
+}
+
+// releaseCoupon borra el canje de una orden cancelada. Se llama con el mutex
+// tomado.
+func (repo *MemoryRepository) releaseCoupon(orderID string) {
+	repo.redemptions = slices.DeleteFunc(repo.redemptions, func(redemption *models.CouponRedemption) bool { return redemption.OrderId == orderID })
+}
+
+func copyCoupon(coupon *models.Coupon) *models.Coupon {
+	copied := *coupon
+	copied.ProductIds = slices.Clone(coupon.ProductIds)
+	copied.CategoryIds = slices.Clone(coupon.CategoryIds)
+	return &copied
+}
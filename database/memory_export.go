@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// EachProduct copia los productos que pasan el filtro y llama a fn sin el
+// mutex tomado, para que un cliente lento no bloquee al resto.
+func (repo *MemoryRepository) EachProduct(ctx context.Context, filter models.ProductFilter, fn func(*models.Products) error) error {
+	repo.mutex.RLock()
+	var inCategory map[string]bool
+	if filter.CategoryId != "" {
+		inCategory = repo.categoryProductIds(filter.CategoryId)
+	}
+	var matching []*models.Products
+	for _, id := range repo.productIds {
+		product := repo.products[id]
+		if !filter.Matches(product) || (inCategory != nil && !inCategory[id]) {
+			continue
+		}
+		if filter.After != nil && filter.After.Compare(product) <= 0 {
+			continue
+		}
+		copied := *product
+		matching = append(matching, &copied)
+	}
+	repo.mutex.RUnlock()
+
+	sortProducts(matching, filter)
+	for _, product := range matching {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertProductImage(ctx context.Context, image *models.ProductImage) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	image.Position = 0
+	for _, stored := range repo.productImages[image.ProductId] {
+		image.Position = max(image.Position, stored.Position+1)
+	}
+	image.Created_at = time.Now()
+	stored := *image
+	repo.productImages[image.ProductId] = append(repo.productImages[image.ProductId], &stored)
+	return nil
+}
+
+func (repo *MemoryRepository) ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	images := []*models.ProductImage{}
+	for _, stored := range repo.productImages[productID] {
+		image := *stored
+		images = append(images, &image)
+	}
+	slices.SortStableFunc(images, func(a, b *models.ProductImage) int {
+		return cmp.Compare(a.Position, b.Position)
+	})
+	return images, nil
+}
+
+func (repo *MemoryRepository) DeleteProductImage(ctx context.Context, productID string, id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	repo.productImages[productID] = slices.DeleteFunc(repo.productImages[productID], func(image *models.ProductImage) bool {
+		return image.Id == id
+	})
+	return nil
+}
+
+func (repo *MemoryRepository) ReorderProductImages(ctx context.Context, productID string, ids []string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	for _, stored := range repo.productImages[productID] {
+		if position := slices.Index(ids, stored.Id); position >= 0 {
+			stored.Position = position
+		}
+	}
+	return nil
+}
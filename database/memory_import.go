@@ -0,0 +1,23 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertProducts(ctx context.Context, products []*models.Products) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	now := time.Now()
+	for _, product := range products {
+		stored := *product
+		stored.Created_at = now
+		stored.Updated_at = now
+		repo.products[product.Id] = &stored
+		repo.productIds = append(repo.productIds, product.Id)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) GetInventory(ctx context.Context, productID string) (*models.Inventory, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.inventory[productID]
+	if !ok {
+		return nil, nil
+	}
+	inventory := *stored
+	return &inventory, nil
+}
+
+func (repo *MemoryRepository) ListInventories(ctx context.Context, productIDs []string) ([]*models.Inventory, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	inventories := []*models.Inventory{}
+	for _, id := range productIDs {
+		inventory := models.Inventory{ProductId: id}
+		if stored, ok := repo.inventory[id]; ok {
+			inventory = *stored
+		}
+		inventory.Variants = []*models.VariantStock{}
+		for _, variantID := range repo.variantIds {
+			if variant := repo.variants[variantID]; variant.ProductId == id {
+				inventory.Variants = append(inventory.Variants, &models.VariantStock{Id: variant.Id, Sku: variant.Sku, Stock: variant.Stock})
+			}
+		}
+		if inventory.Tracked || len(inventory.Variants) > 0 {
+			inventories = append(inventories, &inventory)
+		}
+	}
+	return inventories, nil
+}
+
+func (repo *MemoryRepository) GetCatalogVersion(ctx context.Context) (*models.CatalogVersion, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	version := &models.CatalogVersion{Updated_at: repo.catalogDeletedAt, Products: len(repo.products)}
+	for _, product := range repo.products {
+		if product.Updated_at.After(version.Updated_at) {
+			version.Updated_at = product.Updated_at
+		}
+	}
+	for _, inventory := range repo.inventory {
+		if inventory.Updated_at.After(version.Updated_at) {
+			version.Updated_at = inventory.Updated_at
+		}
+	}
+	for _, variant := range repo.variants {
+		if variant.Updated_at.After(version.Updated_at) {
+			version.Updated_at = variant.Updated_at
+		}
+	}
+	return version, nil
+}
+
+func (repo *MemoryRepository) SetInventory(ctx context.Context, productID string, stock *int) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if stock == nil {
+		if _, ok := repo.inventory[productID]; ok {
+			delete(repo.inventory, productID)
+			repo.catalogDeletedAt = time.Now()
+		}
+		return nil
+	}
+	stored, ok := repo.inventory[productID]
+	if !ok {
+		stored = &models.Inventory{ProductId: productID, Tracked: true}
+		repo.inventory[productID] = stored
+	}
+	stored.Stock = *stock
+	stored.Updated_at = time.Now()
+	return nil
+}
+
+func (repo *MemoryRepository) ExpireReservations(ctx context.Context, now time.Time) (int, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	expired := 0
+	for _, reservation := range repo.reservations {
+		if reservation.Status != models.ReservationActive || reservation.Expires_at.After(now) {
+			continue
+		}
+		order, ok := repo.orders[reservation.OrderId]
+		if !ok || order.Status != models.OrderPending {
+			continue
+		}
+		order.Status = models.OrderCancelled
+		order.Updated_at = now
+		repo.settleReservations(order.Id, models.OrderCancelled)
+		repo.releaseCoupon(order.Id)
+		expired++
+	}
+	return expired, nil
+}
+
+// reserveStock comprueba primero todos los items para no dejar reservas a
+// medias. Los items con variante descuentan el stock de la variante, que
+// siempre se controla; el resto el inventario del producto, si lo tiene. Se
+// llama con el mutex tomado.
+func (repo *MemoryRepository) reserveStock(order *models.Order) error {
+	for _, item := range order.Items {
+		if item.VariantId != "" {
+			variant, ok := repo.variants[item.VariantId]
+			if !ok || variant.Stock < item.Quantity {
+				return fmt.Errorf("%w: %s %s", models.ErrOutOfStock, item.Title, item.Sku)
+			}
+			continue
+		}
+		if stored, ok := repo.inventory[item.ProductId]; ok && stored.Stock < item.Quantity {
+			return fmt.Errorf("%w: %s", models.ErrOutOfStock, item.Title)
+		}
+	}
+
+	now := time.Now()
+	for _, item := range order.Items {
+		if item.VariantId != "" {
+			variant := repo.variants[item.VariantId]
+			variant.Stock -= item.Quantity
+			variant.Updated_at = now
+		} else {
+			stored, ok := repo.inventory[item.ProductId]
+			if !ok {
+				continue
+			}
+			stored.Stock -= item.Quantity
+			stored.Reserved += item.Quantity
+			stored.Updated_at = now
+		}
+		repo.reservations = append(repo.reservations, &models.Reservation{
+			OrderId:    order.Id,
+			ProductId:  item.ProductId,
+			VariantId:  item.VariantId,
+			Quantity:   item.Quantity,
+			Status:     models.ReservationActive,
+			Expires_at: now.Add(models.ReservationTTL),
+			Created_at: now,
+		})
+	}
+	return nil
+}
+
+// settleReservations confirma o libera las reservas de la orden según su nuevo
+// estado. Se llama con el mutex tomado.
+func (repo *MemoryRepository) settleReservations(orderID string, status models.OrderStatus) {
+	for _, reservation := range repo.reservations {
+		if reservation.OrderId != orderID {
+			continue
+		}
+		if reservation.VariantId != "" {
+			repo.settleVariantReservation(reservation, status)
+			continue
+		}
+		stored := repo.inventory[reservation.ProductId]
+		switch {
+		case status == models.OrderPaid && reservation.Status == models.ReservationActive:
+			if stored != nil {
+				stored.Reserved = max(stored.Reserved-reservation.Quantity, 0)
+			}
+			reservation.Status = models.ReservationCommitted
+		case status == models.OrderCancelled && reservation.Status != models.ReservationReleased:
+			if stored != nil {
+				stored.Stock += reservation.Quantity
+				if reservation.Status == models.ReservationActive {
+					stored.Reserved = max(stored.Reserved-reservation.Quantity, 0)
+				}
+			}
+			reservation.Status = models.ReservationReleased
+		default:
+			continue
+		}
+		if stored != nil {
+			stored.Updated_at = time.Now()
+		}
+	}
+}
+
+// settleVariantReservation hace lo mismo para una variante, que no lleva
+// cuenta de unidades reservadas. Se llama con el mutex tomado.
+func (repo *MemoryRepository) settleVariantReservation(reservation *models.Reservation, status models.OrderStatus) {
+	switch {
+	case status == models.OrderPaid && reservation.Status == models.ReservationActive:
+		reservation.Status = models.ReservationCommitted
+	case status == models.OrderCancelled && reservation.Status != models.ReservationReleased:
+		if variant, ok := repo.variants[reservation.VariantId]; ok {
+			variant.Stock += reservation.Quantity
+			variant.Updated_at = time.Now()
+		}
+		reservation.Status = models.ReservationReleased
+	}
+}
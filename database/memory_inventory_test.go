@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func TestMemoryReservations(t *testing.T) {
+	tests := []struct {
+		name         string
+		stock        *int
+		quantity     int
+		transitions  []models.OrderStatus
+		expire       bool
+		wantErr      error
+		wantStock    int
+		wantReserved int
+		wantStatus   models.OrderStatus
+	}{
+		{name: "reserve", stock: intPtr(5), quantity: 2, wantStock: 3, wantReserved: 2, wantStatus: models.OrderPending},
+		{name: "whole stock", stock: intPtr(2), quantity: 2, wantStock: 0, wantReserved: 2, wantStatus: models.OrderPending},
+		{name: "out of stock", stock: intPtr(1), quantity: 2, wantErr: models.ErrOutOfStock, wantStock: 1},
+		{name: "untracked product", quantity: 50, wantStatus: models.OrderPending},
+		{name: "paid commits", stock: intPtr(5), quantity: 2, transitions: []models.OrderStatus{models.OrderPaid}, wantStock: 3, wantStatus: models.OrderPaid},
+		{name: "cancel releases", stock: intPtr(5), quantity: 2, transitions: []models.OrderStatus{models.OrderCancelled}, wantStock: 5, wantStatus: models.OrderCancelled},
+		{name: "cancel after paid restocks", stock: intPtr(5), quantity: 2, transitions: []models.OrderStatus{models.OrderPaid, models.OrderCancelled}, wantStock: 5, wantStatus: models.OrderCancelled},
+		{name: "expire pending", stock: intPtr(5), quantity: 2, expire: true, wantStock: 5, wantStatus: models.OrderCancelled},
+		{name: "expire skips paid", stock: intPtr(5), quantity: 2, transitions: []models.OrderStatus{models.OrderPaid}, expire: true, wantStock: 3, wantStatus: models.OrderPaid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewMemoryRepository()
+			if err := repo.SetInventory(ctx, "p1", tt.stock); err != nil {
+				t.Fatal(err)
+			}
+
+			order := newTestOrder("o1", &models.OrderItem{ProductId: "p1", Title: "Lamp", Quantity: tt.quantity})
+			err := repo.InsertOrder(ctx, order)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InsertOrder error = %v, want %v", err, tt.wantErr)
+			}
+			status := models.OrderPending
+			for _, next := range tt.transitions {
+				if err := repo.UpdateOrderStatus(ctx, order.Id, status, next); err != nil {
+					t.Fatalf("UpdateOrderStatus(%s -> %s) error = %v", status, next, err)
+				}
+				status = next
+			}
+			if tt.expire {
+				if _, err := repo.ExpireReservations(ctx, time.Now().Add(models.ReservationTTL+time.Minute)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.stock != nil {
+				inventory, _ := repo.GetInventory(ctx, "p1")
+				if inventory.Stock != tt.wantStock || inventory.Reserved != tt.wantReserved {
+					t.Errorf("inventory = stock %d reserved %d, want stock %d reserved %d", inventory.Stock, inventory.Reserved, tt.wantStock, tt.wantReserved)
+				}
+			}
+			stored, _ := repo.GetOrderById(ctx, order.Id)
+			if tt.wantErr != nil {
+				if stored != nil {
+					t.Errorf("order was stored after %v", tt.wantErr)
+				}
+				return
+			}
+			if stored == nil || stored.Status != tt.wantStatus {
+				t.Errorf("order = %+v, want status %s", stored, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMemoryReservationsAreAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	repo.SetInventory(ctx, "p1", intPtr(5))
+	repo.SetInventory(ctx, "p2", intPtr(1))
+
+	short := newTestOrder("o1",
+		&models.OrderItem{ProductId: "p1", Title: "Lamp", Quantity: 2},
+		&models.OrderItem{ProductId: "p2", Title: "Desk", Quantity: 2},
+	)
+	if err := repo.InsertOrder(ctx, short); !errors.Is(err, models.ErrOutOfStock) {
+		t.Fatalf("InsertOrder error = %v, want ErrOutOfStock", err)
+	}
+	if inventory, _ := repo.GetInventory(ctx, "p1"); inventory.Stock != 5 || inventory.Reserved != 0 {
+		t.Errorf("p1 after failed order = %+v, want untouched", inventory)
+	}
+}
+
+func TestMemoryVariantReservations(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		quantity  int
+		next      models.OrderStatus
+		wantErr   error
+		wantStock int
+	}{
+		{"variant out of stock", 5, "", models.ErrOutOfStock, 4},
+		{"variant reserved", 3, "", nil, 1},
+		{"variant released on cancel", 3, models.OrderCancelled, nil, 4},
+		{"variant kept when paid", 3, models.OrderPaid, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewMemoryRepository()
+			repo.InsertVariant(ctx, &models.ProductVariant{Id: "v1", ProductId: "p3", Sku: "P3-RED", Stock: 4})
+
+			order := newTestOrder("o1", &models.OrderItem{ProductId: "p3", VariantId: "v1", Sku: "P3-RED", Quantity: tt.quantity})
+			if err := repo.InsertOrder(ctx, order); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InsertOrder error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.next != "" {
+				if err := repo.UpdateOrderStatus(ctx, order.Id, models.OrderPending, tt.next); err != nil {
+					t.Fatal(err)
+				}
+			}
+			variant, _ := repo.GetVariantById(ctx, "v1")
+			if variant.Stock != tt.wantStock {
+				t.Errorf("variant stock = %d, want %d", variant.Stock, tt.wantStock)
+			}
+		})
+	}
+}
+
+func newTestOrder(id string, items ...*models.OrderItem) *models.Order {
+	return &models.Order{Id: id, UserId: "u1", Status: models.OrderPending, Items: items}
+}
+
+func intPtr(value int) *int {
+	return &value
+}
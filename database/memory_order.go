@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertOrder(ctx context.Context, order *models.Order) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	now := time.Now()
+	if order.CouponCode != "" {
+		if err := repo.checkCoupon(order, now); err != nil {
+			return err
+		}
+	}
+	if err := repo.reserveStock(order); err != nil {
+		return err
+	}
+	order.Created_at = now
+	order.Updated_at = now
+	if order.CouponCode != "" {
+		repo.redemptions = append(repo.redemptions, &models.CouponRedemption{
+			Code:       order.CouponCode,
+			UserId:     order.UserId,
+			OrderId:    order.Id,
+			Discount:   order.Discount,
+			Created_at: now,
+		})
+	}
+	repo.orders[order.Id] = copyOrder(order)
+	repo.orderIds = append(repo.orderIds, order.Id)
+	repo.removeOrderedCartItems(order)
+	return nil
+}
+
+// removeOrderedCartItems descuenta del carrito las cantidades de la orden; lo
+// agregado después de leer el carrito se queda. Se llama con el mutex tomado.
+func (repo *MemoryRepository) removeOrderedCartItems(order *models.Order) {
+	stored, ok := repo.carts[order.UserId]
+	if !ok {
+		return
+	}
+	for _, item := range order.Items {
+		key := cartKey{item.ProductId, item.VariantId}
+		quantity, ok := stored.quantities[key]
+		if !ok {
+			continue
+		}
+		if quantity <= item.Quantity {
+			stored.remove(key)
+		} else {
+			stored.quantities[key] = quantity - item.Quantity
+		}
+	}
+	if stored.couponCode == order.CouponCode {
+		stored.couponCode = ""
+	}
+	if len(stored.keys) == 0 && stored.couponCode == "" {
+		delete(repo.carts, order.UserId)
+	}
+}
+
+func (repo *MemoryRepository) GetOrderById(ctx context.Context, id string) (*models.Order, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyOrder(stored), nil
+}
+
+func (repo *MemoryRepository) ListOrders(ctx context.Context, userID string) ([]*models.Order, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	orders := []*models.Order{}
+	for i := len(repo.orderIds) - 1; i >= 0; i-- {
+		stored := repo.orders[repo.orderIds[i]]
+		if stored.UserId == userID {
+			orders = append(orders, copyOrder(stored))
+		}
+	}
+	return orders, nil
+}
+
+func (repo *MemoryRepository) UpdateOrderStatus(ctx context.Context, id string, from models.OrderStatus, to models.OrderStatus) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.orders[id]
+	if !ok || stored.Status != from {
+		return models.ErrInvalidOrderTransition
+	}
+	stored.Status = to
+	stored.Updated_at = time.Now()
+	repo.settleReservations(id, to)
+	if to == models.OrderCancelled {
+		repo.releaseCoupon(id)
+	}
+	return nil
+}
+
+func copyOrder(order *models.Order) *models.Order {
+	copied := *order
+	if order.Shipping != nil {
+		shipping := *order.Shipping
+		copied.Shipping = &shipping
+	}
+	if order.ShippingAddress != nil {
+		address := *order.ShippingAddress
+		copied.ShippingAddress = &address
+	}
+	copied.Items = make([]*models.OrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		copiedItem := *item
+		if item.Tax != nil {
+			tax := *item.Tax
+			copiedItem.Tax = &tax
+		}
+		copied.Items = append(copied.Items, &copiedItem)
+	}
+	return &copied
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertPayment(ctx context.Context, payment *models.Payment) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	payment.Created_at = time.Now()
+	payment.Updated_at = payment.Created_at
+	stored := *payment
+	repo.payments = append(repo.payments, &stored)
+	return nil
+}
+
+func (repo *MemoryRepository) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	for _, stored := range repo.payments {
+		if stored.Id == payment.Id {
+			payment.Updated_at = time.Now()
+			stored.Reference = payment.Reference
+			stored.Status = payment.Status
+			stored.Error = payment.Error
+			stored.Updated_at = payment.Updated_at
+			return nil
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) GetPaymentByReference(ctx context.Context, reference string) (*models.Payment, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	for _, stored := range repo.payments {
+		if reference != "" && stored.Reference == reference {
+			payment := *stored
+			return &payment, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *MemoryRepository) ListPayments(ctx context.Context, orderID string) ([]*models.Payment, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	payments := []*models.Payment{}
+	for _, stored := range repo.payments {
+		if stored.OrderId == orderID {
+			payment := *stored
+			payments = append(payments, &payment)
+		}
+	}
+	return payments, nil
+}
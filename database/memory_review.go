@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertReview(ctx context.Context, review *models.Review) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	for _, stored := range repo.reviews {
+		if stored.ProductId == review.ProductId && stored.UserId == review.UserId {
+			return models.ErrDuplicateReview
+		}
+	}
+	review.Created_at = time.Now()
+	stored := *review
+	repo.reviews = append(repo.reviews, &stored)
+	return nil
+}
+
+func (repo *MemoryRepository) ListReviews(ctx context.Context, productID string, page uint64) ([]*models.Review, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	// Las reseñas se guardan en orden de creación; se devuelven de la más nueva
+	// a la más vieja.
+	matching := []*models.Review{}
+	for i := len(repo.reviews) - 1; i >= 0; i-- {
+		if stored := repo.reviews[i]; stored.ProductId == productID {
+			review := *stored
+			matching = append(matching, &review)
+		}
+	}
+	start := page * reviewPageSize
+	if start >= uint64(len(matching)) {
+		return []*models.Review{}, nil
+	}
+	return matching[start:min(start+reviewPageSize, uint64(len(matching)))], nil
+}
+
+func (repo *MemoryRepository) GetReviewSummary(ctx context.Context, productID string) (*models.ReviewSummary, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	summary := &models.ReviewSummary{}
+	total := 0
+	for _, stored := range repo.reviews {
+		if stored.ProductId == productID {
+			summary.ReviewCount++
+			total += stored.Rating
+		}
+	}
+	if summary.ReviewCount > 0 {
+		// Promedio en centésimas con enteros, redondeado como ROUND(AVG(rating), 2).
+		hundredths := (total*200 + summary.ReviewCount) / (2 * summary.ReviewCount)
+		summary.AverageRating = float64(hundredths) / 100
+	}
+	return summary, nil
+}
+
+func (repo *MemoryRepository) HasDeliveredOrder(ctx context.Context, userID string, productID string) (bool, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	for _, order := range repo.orders {
+		if order.UserId != userID || order.Status != models.OrderDelivered {
+			continue
+		}
+		if slices.ContainsFunc(order.Items, func(item *models.OrderItem) bool { return item.ProductId == productID }) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
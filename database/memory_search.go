@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+const snippetWords = 15
+
+// SearchProducts hace una búsqueda simple por palabras: todas las palabras de
+// la consulta deben aparecer como prefijo de alguna palabra del título o la
+// descripción. Las coincidencias en el título pesan el doble.
+func (repo *MemoryRepository) SearchProducts(ctx context.Context, search models.ProductSearch) (*models.ProductSearchPage, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	terms := tokenize(search.Query)
+	results := []*models.ProductSearchResult{}
+	if len(terms) == 0 {
+		return models.NewProductSearchPage(search, results), nil
+	}
+
+	for _, id := range repo.productIds {
+		product := repo.products[id]
+		rank, ok := rankProduct(product, terms)
+		if !ok {
+			continue
+		}
+		result := &models.ProductSearchResult{Products: *product, Rank: rank}
+		if search.After != nil && search.After.Compare(result) <= 0 {
+			continue
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Id < results[j].Id
+	})
+	if len(results) > search.Limit+1 {
+		results = results[:search.Limit+1]
+	}
+	for _, result := range results {
+		result.Snippet = highlight(result.Title+" "+result.Description, terms)
+	}
+	return models.NewProductSearchPage(search, results), nil
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func rankProduct(product *models.Products, terms []string) (float64, bool) {
+	title := tokenize(product.Title)
+	description := tokenize(product.Description)
+
+	var rank float64
+	for _, term := range terms {
+		found := false
+		for _, word := range title {
+			if strings.HasPrefix(word, term) {
+				rank += 1.0
+				found = true
+			}
+		}
+		for _, word := range description {
+			if strings.HasPrefix(word, term) {
+				rank += 0.5
+				found = true
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return rank, true
+}
+
+// highlight devuelve una ventana de palabras alrededor de la primera
+// coincidencia, marcando cada coincidencia con <mark>. El texto del vendedor
+// se escapa, así el fragmento se puede mostrar como HTML.
+func highlight(text string, terms []string) string {
+	words := strings.Fields(text)
+	first := 0
+	for i, word := range words {
+		if matchesTerm(strings.Join(tokenize(word), ""), terms) {
+			first = i
+			break
+		}
+	}
+
+	start := first - snippetWords/3
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	snippet := make([]string, 0, end-start)
+	for _, word := range words[start:end] {
+		escaped := html.EscapeString(word)
+		if matchesTerm(strings.Join(tokenize(word), ""), terms) {
+			escaped = "<mark>" + escaped + "</mark>"
+		}
+		snippet = append(snippet, escaped)
+	}
+	return strings.Join(snippet, " ")
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertShippingMethod(ctx context.Context, method *models.ShippingMethod) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	method.Created_at = time.Now()
+	repo.shippingMethods[method.Id] = copyShippingMethod(method)
+	return nil
+}
+
+func (repo *MemoryRepository) GetShippingMethodById(ctx context.Context, id string) (*models.ShippingMethod, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.shippingMethods[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyShippingMethod(stored), nil
+}
+
+func (repo *MemoryRepository) ListShippingMethods(ctx context.Context) ([]*models.ShippingMethod, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	methods := []*models.ShippingMethod{}
+	for _, stored := range repo.shippingMethods {
+		methods = append(methods, copyShippingMethod(stored))
+	}
+	slices.SortFunc(methods, func(a, b *models.ShippingMethod) int {
+		if order := a.Created_at.Compare(b.Created_at); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return methods, nil
+}
+
+func (repo *MemoryRepository) UpdateShippingMethod(ctx context.Context, method *models.ShippingMethod) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.shippingMethods[method.Id]
+	if !ok {
+		return nil
+	}
+	updated := copyShippingMethod(method)
+	updated.Created_at = stored.Created_at
+	repo.shippingMethods[method.Id] = updated
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteShippingMethod(ctx context.Context, id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.shippingMethods[id]; !ok {
+		return models.ErrShippingMethodNotFound
+	}
+	delete(repo.shippingMethods, id)
+	return nil
+}
+
+func copyShippingMethod(method *models.ShippingMethod) *models.ShippingMethod {
+	copied := *method
+	copied.Regions = slices.Clone(method.Regions)
+	return &copied
+}
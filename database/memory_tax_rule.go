@@ -0,0 +1,77 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertTaxRule(ctx context.Context, rule *models.TaxRule) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	rule.Created_at = time.Now()
+	stored := *rule
+	repo.taxRules[rule.Id] = &stored
+	return nil
+}
+
+func (repo *MemoryRepository) GetTaxRuleById(ctx context.Context, id string) (*models.TaxRule, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.taxRules[id]
+	if !ok {
+		return nil, nil
+	}
+	rule := *stored
+	return &rule, nil
+}
+
+func (repo *MemoryRepository) ListTaxRules(ctx context.Context) ([]*models.TaxRule, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	rules := []*models.TaxRule{}
+	for _, stored := range repo.taxRules {
+		rule := *stored
+		rules = append(rules, &rule)
+	}
+	slices.SortFunc(rules, func(a, b *models.TaxRule) int {
+		if order := cmp.Compare(a.Region, b.Region); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return rules, nil
+}
+
+func (repo *MemoryRepository) UpdateTaxRule(ctx context.Context, rule *models.TaxRule) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.taxRules[rule.Id]
+	if !ok {
+		return nil
+	}
+	stored.Region = rule.Region
+	stored.CategoryId = rule.CategoryId
+	stored.TaxClass = rule.TaxClass
+	stored.RateBps = rule.RateBps
+	stored.Inclusive = rule.Inclusive
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteTaxRule(ctx context.Context, id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.taxRules[id]; !ok {
+		return models.ErrTaxRuleNotFound
+	}
+	delete(repo.taxRules, id)
+	return nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) InsertRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	token.Created_at = time.Now()
+	stored := *token
+	repo.refreshTokens[token.Hash] = &stored
+	return nil
+}
+
+func (repo *MemoryRepository) GetRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.refreshTokens[hash]
+	if !ok {
+		return nil, nil
+	}
+	token := *stored
+	return &token, nil
+}
+
+func (repo *MemoryRepository) RevokeRefreshToken(ctx context.Context, hash string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.refreshTokens[hash]
+	if !ok || stored.Revoked {
+		return models.ErrRefreshTokenRevoked
+	}
+	stored.Revoked = true
+	return nil
+}
+
+func (repo *MemoryRepository) RevokeRefreshTokens(ctx context.Context, userID string, familyID string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	for _, stored := range repo.refreshTokens {
+		if stored.UserId == userID && (familyID == "" || stored.FamilyId == familyID) {
+			stored.Revoked = true
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	now := time.Now()
+	for revokedJti, expires := range repo.revokedTokens {
+		if expires.Before(now) {
+			delete(repo.revokedTokens, revokedJti)
+		}
+	}
+	repo.revokedTokens[jti] = expiresAt
+	return nil
+}
+
+func (repo *MemoryRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	_, ok := repo.revokedTokens[jti]
+	return ok, nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *MemoryRepository) SetProductOptions(ctx context.Context, productID string, options []*models.ProductOption) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored := make([]*models.ProductOption, 0, len(options))
+	for _, option := range options {
+		stored = append(stored, copyOption(option))
+	}
+	repo.productOptions[productID] = stored
+	return nil
+}
+
+func (repo *MemoryRepository) ListProductOptions(ctx context.Context, productID string) ([]*models.ProductOption, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	options := []*models.ProductOption{}
+	for _, option := range repo.productOptions[productID] {
+		options = append(options, copyOption(option))
+	}
+	return options, nil
+}
+
+func (repo *MemoryRepository) InsertVariant(ctx context.Context, variant *models.ProductVariant) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.skuInUse(variant.Sku, variant.Id) {
+		return models.ErrDuplicateSku
+	}
+	variant.Created_at = time.Now()
+	variant.Updated_at = variant.Created_at
+	repo.variants[variant.Id] = copyVariant(variant)
+	repo.variantIds = append(repo.variantIds, variant.Id)
+	return nil
+}
+
+func (repo *MemoryRepository) GetVariantById(ctx context.Context, id string) (*models.ProductVariant, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored, ok := repo.variants[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyVariant(stored), nil
+}
+
+func (repo *MemoryRepository) ListVariants(ctx context.Context, productID string) ([]*models.ProductVariant, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	variants := []*models.ProductVariant{}
+	for _, id := range repo.variantIds {
+		if stored := repo.variants[id]; stored.ProductId == productID {
+			variants = append(variants, copyVariant(stored))
+		}
+	}
+	return variants, nil
+}
+
+func (repo *MemoryRepository) UpdateVariant(ctx context.Context, variant *models.ProductVariant) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	stored, ok := repo.variants[variant.Id]
+	if !ok || stored.ProductId != variant.ProductId {
+		return nil
+	}
+	if repo.skuInUse(variant.Sku, variant.Id) {
+		return models.ErrDuplicateSku
+	}
+	updated := copyVariant(variant)
+	updated.Created_at = stored.Created_at
+	updated.Updated_at = time.Now()
+	repo.variants[variant.Id] = updated
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteVariant(ctx context.Context, productID string, id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if stored, ok := repo.variants[id]; ok && stored.ProductId == productID {
+		delete(repo.variants, id)
+		repo.variantIds = removeId(repo.variantIds, id)
+		repo.catalogDeletedAt = time.Now()
+		for _, cart := range repo.carts {
+			cart.removeMatching(func(key cartKey) bool { return key.variantId == id })
+		}
+	}
+	return nil
+}
+
+// deleteProductVariants borra opciones y variantes de un producto eliminado.
+// Se llama con el mutex tomado.
+func (repo *MemoryRepository) deleteProductVariants(productID string) {
+	delete(repo.productOptions, productID)
+	for id, stored := range repo.variants {
+		if stored.ProductId == productID {
+			delete(repo.variants, id)
+			repo.variantIds = removeId(repo.variantIds, id)
+		}
+	}
+}
+
+func (repo *MemoryRepository) skuInUse(sku string, exceptID string) bool {
+	for id, stored := range repo.variants {
+		if stored.Sku == sku && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func copyOption(option *models.ProductOption) *models.ProductOption {
+	return &models.ProductOption{
+		Name:   option.Name,
+		Values: append([]string(nil), option.Values...),
+	}
+}
+
+func copyVariant(variant *models.ProductVariant) *models.ProductVariant {
+	copied := *variant
+	copied.Options = maps.Clone(variant.Options)
+	if variant.Price != nil {
+		price := *variant.Price
+		copied.Price = &price
+	}
+	return &copied
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+type memoryWishlistItem struct {
+	productId string
+	addedAt   time.Time
+}
+
+func (repo *MemoryRepository) ListWishlist(ctx context.Context, userID string) ([]*models.WishlistItem, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	stored := repo.wishlists[userID]
+	items := []*models.WishlistItem{}
+	for i := len(stored) - 1; i >= 0; i-- {
+		item := &models.WishlistItem{ProductId: stored[i].productId, Added_at: stored[i].addedAt}
+		if product, ok := repo.products[item.ProductId]; ok {
+			copied := *product
+			item.Product = &copied
+		} else {
+			item.Deleted = true
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (repo *MemoryRepository) AddWishlistItem(ctx context.Context, userID string, productID string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if slices.ContainsFunc(repo.wishlists[userID], func(item memoryWishlistItem) bool { return item.productId == productID }) {
+		return nil
+	}
+	repo.wishlists[userID] = append(repo.wishlists[userID], memoryWishlistItem{productId: productID, addedAt: time.Now()})
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteWishlistItem(ctx context.Context, userID string, productID string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	index := slices.IndexFunc(repo.wishlists[userID], func(item memoryWishlistItem) bool { return item.productId == productID })
+	if index < 0 {
+		return models.ErrWishlistItemNotFound
+	}
+	repo.wishlists[userID] = slices.Delete(repo.wishlists[userID], index, index+1)
+	return nil
+}
+
+func (repo *MemoryRepository) ClearWishlist(ctx context.Context, userID string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	delete(repo.wishlists, userID)
+	return nil
+}
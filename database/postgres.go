@@ -15,8 +15,7 @@ type PostgresRepository struct {
 }
 
 var (
-	product models.Products
-	user    models.User
+	user models.User
 )
 
 func NewPostgresRepository(url string) (*PostgresRepository, error) {
@@ -28,71 +27,50 @@ func NewPostgresRepository(url string) (*PostgresRepository, error) {
 }
 
 func (repo *PostgresRepository) InsertUser(ctx context.Context, user *models.User) error {
-	_, err := repo.db.ExecContext(ctx, "INSERT INTO users (id , email , password) VALUES ($1, $2 ,$3)", user.Id, user.Email, user.Password)
+	_, err := repo.db.ExecContext(ctx, "INSERT INTO users (id , email , password , role) VALUES ($1, $2 ,$3 ,$4)", user.Id, user.Email, user.Password, user.Role)
 	return err
 }
 
 func (repo *PostgresRepository) InsertProduct(ctx context.Context, product *models.Products) error {
-	_, err := repo.db.ExecContext(ctx, "INSERT INTO products (id , title , description , image_url , price , user_id) VALUES ($1, $2 ,$3 ,$4 ,$5 ,$6)", product.Id, product.Title, product.Description, product.ImageUrl, product.Price, product.UserId)
+	_, err := repo.db.ExecContext(ctx, "INSERT INTO products (id , title , description , image_url , price , currency , tax_class , weight_grams , length_mm , width_mm , height_mm , user_id) VALUES ($1, $2 ,$3 ,$4 ,$5 ,$6 ,$7 ,$8 ,$9 ,$10 ,$11 ,$12)", product.Id, product.Title, product.Description, product.ImageUrl, product.Price, product.Currency, product.TaxClass, product.WeightGrams, product.LengthMm, product.WidthMm, product.HeightMm, product.UserId)
 	return err
 }
 
 func (repo *PostgresRepository) UpdateProduct(ctx context.Context, product *models.Products) error {
-	_, err := repo.db.ExecContext(ctx, "UPDATE products SET title = $1, description = $2, image_url = $3, price = $4 WHERE id = $5 and user_id = $6", product.Title, product.Description, product.ImageUrl, product.Price, product.Id, product.UserId)
-	return err
-}
-func (repo *PostgresRepository) GetUserById(ctx context.Context, id string) (*models.User, error) {
-	rows, err := repo.db.QueryContext(ctx, "SELECT id , email FROM users WHERE id = $1", id)
-
+	result, err := repo.db.ExecContext(ctx, "UPDATE products SET title = $1, description = $2, image_url = $3, price = $4, currency = $5, tax_class = $6, weight_grams = $7, length_mm = $8, width_mm = $9, height_mm = $10, updated_at = NOW() WHERE id = $11 and user_id = $12", product.Title, product.Description, product.ImageUrl, product.Price, product.Currency, product.TaxClass, product.WeightGrams, product.LengthMm, product.WidthMm, product.HeightMm, product.Id, product.UserId)
 	if err != nil {
-		log.Fatal(err)
-		return &user, nil
+		return err
 	}
-
-	defer func() {
-		err = rows.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}()
-	for rows.Next() {
-		if err = rows.Scan(&user.Id, &user.Email); err != nil {
-			return &user, nil
-		}
+	return productAffected(result)
+}
+func (repo *PostgresRepository) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	err := repo.db.QueryRowContext(ctx, "SELECT id , email , role FROM users WHERE id = $1", id).
+		Scan(&user.Id, &user.Email, &user.Role)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	if err = rows.Err(); err != nil {
+	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 func (repo *PostgresRepository) GetProductById(ctx context.Context, id string) (*models.Products, error) {
-	rows, err := repo.db.QueryContext(ctx, "SELECT id , title , description , image_url , price ,created_at,user_id FROM products WHERE id = $1", id)
-
-	if err != nil {
-		log.Fatal(err)
-		return &product, nil
-	}
-
-	defer func() {
-		err = rows.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}()
-	for rows.Next() {
-		if err = rows.Scan(&product.Id, &product.Title, &product.Description, &product.ImageUrl, &product.Price, &product.Created_at, &product.UserId); err != nil {
-			return &product, nil
-		}
+	var product models.Products
+	err := repo.db.QueryRowContext(ctx, "SELECT id , title , description , image_url , currency , price , tax_class , weight_grams , length_mm , width_mm , height_mm ,created_at,updated_at,user_id FROM products WHERE id = $1", id).
+		Scan(&product.Id, &product.Title, &product.Description, &product.ImageUrl, &product.Currency, priceIn(&product), &product.TaxClass, &product.WeightGrams, &product.LengthMm, &product.WidthMm, &product.HeightMm, &product.Created_at, &product.Updated_at, &product.UserId)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	if err = rows.Err(); err != nil {
+	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
 func (repo *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	rows, err := repo.db.QueryContext(ctx, "SELECT id, email , password FROM users WHERE email = $1", email)
+	rows, err := repo.db.QueryContext(ctx, "SELECT id, email , password , role FROM users WHERE email = $1", email)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +81,7 @@ func (repo *PostgresRepository) GetUserByEmail(ctx context.Context, email string
 	}()
 
 	if rows.Next() {
-		if err := rows.Scan(&user.Id, &user.Email, &user.Password); err != nil {
+		if err := rows.Scan(&user.Id, &user.Email, &user.Password, &user.Role); err != nil {
 			return nil, err
 		}
 		return &user, nil
@@ -115,14 +93,35 @@ func (repo *PostgresRepository) GetUserByEmail(ctx context.Context, email string
 	return nil, nil
 }
 
-func (repo *PostgresRepository) DeleteProduct(ctx context.Context, id string, userdID string) error {
-	_, err := repo.db.ExecContext(ctx, "DELETE FROM products WHERE id = $1 and user_id = $2", id, userdID)
+func (repo *PostgresRepository) UpdateUserRole(ctx context.Context, id string, role models.Role) error {
+	_, err := repo.db.ExecContext(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, id)
 	return err
 }
 
-func (repo *PostgresRepository) ListProducts(ctx context.Context, page uint64) ([]*models.Products, error) {
+func (repo *PostgresRepository) DeleteProduct(ctx context.Context, id string, userdID string) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM products WHERE id = $1 and user_id = $2", id, userdID)
+	if err != nil {
+		return err
+	}
+	return productAffected(result)
+}
+
+// productAffected devuelve ErrProductNotFound si la escritura filtrada por
+// dueño no tocó ninguna fila.
+func productAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrProductNotFound
+	}
+	return nil
+}
 
-	rows, err := repo.db.QueryContext(ctx, "SELECT * FROM products LIMIT $1 OFFSET $2", 5, page*5)
+func (repo *PostgresRepository) ListProducts(ctx context.Context, filter models.ProductFilter) (*models.ProductPage, error) {
+	query, args := buildProductQuery(filter)
+	rows, err := repo.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -132,16 +131,32 @@ func (repo *PostgresRepository) ListProducts(ctx context.Context, page uint64) (
 			log.Fatal(err)
 		}
 	}()
-	var products []*models.Products
+	products := []*models.Products{}
 	for rows.Next() {
-		if err = rows.Scan(&product.Id, &product.Title, &product.Description, &product.ImageUrl, &product.Price, &product.Created_at, &product.UserId); err == nil {
+		var product models.Products
+		if err = rows.Scan(&product.Id, &product.Title, &product.Description, &product.ImageUrl, &product.Currency, priceIn(&product), &product.TaxClass, &product.WeightGrams, &product.LengthMm, &product.WidthMm, &product.HeightMm, &product.Created_at, &product.Updated_at, &product.UserId); err == nil {
 			products = append(products, &product)
 		}
 	}
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-	return products, nil
+	return newProductPage(filter, products), nil
+}
+
+// priceIn lee el precio con los decimales de la moneda del producto; la
+// columna currency tiene que ir antes en el SELECT para que ya esté leída.
+func priceIn(product *models.Products) sql.Scanner {
+	return productPrice{product}
+}
+
+type productPrice struct {
+	product *models.Products
+}
+
+func (p productPrice) Scan(src any) error {
+	p.product.Price.Currency = p.product.Currency
+	return p.product.Price.Scan(src)
 }
 
 func (repo *PostgresRepository) Close() error {
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+const selectAddressesQuery = "SELECT id, user_id, name, line1, line2, city, region, postal_code, country, phone, default_shipping, default_billing, created_at, updated_at FROM addresses"
+
+func (repo *PostgresRepository) InsertAddress(ctx context.Context, address *models.Address) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := clearDefaultAddresses(ctx, tx, address); err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, "INSERT INTO addresses (id, user_id, name, line1, line2, city, region, postal_code, country, phone, default_shipping, default_billing) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING created_at, updated_at",
+		address.Id, address.UserId, address.Name, address.Line1, address.Line2, address.City, address.Region, address.PostalCode, address.Country, address.Phone, address.DefaultShipping, address.DefaultBilling).
+		Scan(&address.Created_at, &address.Updated_at)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAddressById solo encuentra la dirección si es del usuario.
+func (repo *PostgresRepository) GetAddressById(ctx context.Context, userID string, id string) (*models.Address, error) {
+	addresses, err := repo.queryAddresses(ctx, selectAddressesQuery+" WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	return addresses[0], nil
+}
+
+func (repo *PostgresRepository) ListAddresses(ctx context.Context, userID string) ([]*models.Address, error) {
+	return repo.queryAddresses(ctx, selectAddressesQuery+" WHERE user_id = $1 ORDER BY created_at, id", userID)
+}
+
+func (repo *PostgresRepository) UpdateAddress(ctx context.Context, address *models.Address) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := clearDefaultAddresses(ctx, tx, address); err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, "UPDATE addresses SET name = $1, line1 = $2, line2 = $3, city = $4, region = $5, postal_code = $6, country = $7, phone = $8, default_shipping = $9, default_billing = $10, updated_at = NOW() WHERE id = $11 AND user_id = $12 RETURNING updated_at",
+		address.Name, address.Line1, address.Line2, address.City, address.Region, address.PostalCode, address.Country, address.Phone, address.DefaultShipping, address.DefaultBilling, address.Id, address.UserId).
+		Scan(&address.Updated_at)
+	if err == sql.ErrNoRows {
+		return models.ErrAddressNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (repo *PostgresRepository) DeleteAddress(ctx context.Context, userID string, id string) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM addresses WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrAddressNotFound
+	}
+	return nil
+}
+
+// clearDefaultAddresses le quita la marca de dirección por defecto a las otras
+// direcciones del usuario antes de dársela a esta.
+func clearDefaultAddresses(ctx context.Context, tx *sql.Tx, address *models.Address) error {
+	if address.DefaultShipping {
+		if _, err := tx.ExecContext(ctx, "UPDATE addresses SET default_shipping = FALSE WHERE user_id = $1 AND id <> $2 AND default_shipping", address.UserId, address.Id); err != nil {
+			return err
+		}
+	}
+	if address.DefaultBilling {
+		if _, err := tx.ExecContext(ctx, "UPDATE addresses SET default_billing = FALSE WHERE user_id = $1 AND id <> $2 AND default_billing", address.UserId, address.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *PostgresRepository) queryAddresses(ctx context.Context, query string, args ...any) ([]*models.Address, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	addresses := []*models.Address{}
+	for rows.Next() {
+		var address models.Address
+		if err := rows.Scan(&address.Id, &address.UserId, &address.Name, &address.Line1, &address.Line2, &address.City, &address.Region, &address.PostalCode, &address.Country, &address.Phone, &address.DefaultShipping, &address.DefaultBilling, &address.Created_at, &address.Updated_at); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, &address)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
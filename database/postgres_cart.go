@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *PostgresRepository) GetCart(ctx context.Context, userID string) (*models.Cart, error) {
+	cart := &models.Cart{UserId: userID, Items: []*models.CartItem{}}
+
+	err := repo.db.QueryRowContext(ctx, "SELECT COALESCE(coupon_code, ''), updated_at FROM carts WHERE user_id = $1", userID).
+		Scan(&cart.CouponCode, &cart.Updated_at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cart, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Los items de una variante borrada no se muestran. La moneda va antes que
+	// el precio para que Scan lo lea con sus decimales.
+	rows, err := repo.db.QueryContext(ctx, `SELECT ci.product_id, ci.variant_id, COALESCE(v.sku, ''), p.title, p.currency, COALESCE(v.price, p.price), ci.quantity, p.tax_class, p.weight_grams
+		FROM cart_items ci
+		JOIN products p ON p.id = ci.product_id
+		LEFT JOIN product_variants v ON v.id = ci.variant_id AND v.product_id = ci.product_id
+		WHERE ci.user_id = $1 AND (ci.variant_id = '' OR v.id IS NOT NULL)
+		ORDER BY ci.created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	for rows.Next() {
+		var item models.CartItem
+		if err := rows.Scan(&item.ProductId, &item.VariantId, &item.Sku, &item.Title, &item.Price.Currency, &item.Price, &item.Quantity, &item.TaxClass, &item.WeightGrams); err != nil {
+			return nil, err
+		}
+		cart.Items = append(cart.Items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (repo *PostgresRepository) AddCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO carts (user_id) VALUES ($1) ON CONFLICT (user_id) DO UPDATE SET updated_at = NOW()", userID); err != nil {
+		return err
+	}
+	// Si la suma pasa del tope la fila no cambia y no cuenta como afectada.
+	result, err := tx.ExecContext(ctx, "INSERT INTO cart_items (user_id, product_id, variant_id, quantity) VALUES ($1, $2, $3, $4) ON CONFLICT (user_id, product_id, variant_id) DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity WHERE cart_items.quantity + EXCLUDED.quantity <= $5", userID, productID, variantID, quantity, models.MaxCartItemQuantity)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrCartItemQuantity
+	}
+	return tx.Commit()
+}
+
+func (repo *PostgresRepository) UpdateCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error {
+	result, err := repo.db.ExecContext(ctx, "UPDATE cart_items SET quantity = $1 WHERE user_id = $2 AND product_id = $3 AND variant_id = $4", quantity, userID, productID, variantID)
+	if err != nil {
+		return err
+	}
+	return repo.touchCart(ctx, userID, result)
+}
+
+func (repo *PostgresRepository) DeleteCartItem(ctx context.Context, userID string, productID string, variantID string) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM cart_items WHERE user_id = $1 AND product_id = $2 AND variant_id = $3", userID, productID, variantID)
+	if err != nil {
+		return err
+	}
+	return repo.touchCart(ctx, userID, result)
+}
+
+func (repo *PostgresRepository) touchCart(ctx context.Context, userID string, result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrCartItemNotFound
+	}
+	_, err = repo.db.ExecContext(ctx, "UPDATE carts SET updated_at = NOW() WHERE user_id = $1", userID)
+	return err
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *PostgresRepository) InsertCategory(ctx context.Context, category *models.Category) error {
+	return repo.db.QueryRowContext(ctx, "INSERT INTO categories (id, name, parent_id) VALUES ($1, $2, NULLIF($3, '')) RETURNING created_at", category.Id, category.Name, category.ParentId).
+		Scan(&category.Created_at)
+}
+
+func (repo *PostgresRepository) GetCategoryById(ctx context.Context, id string) (*models.Category, error) {
+	var category models.Category
+	err := repo.db.QueryRowContext(ctx, "SELECT id, name, COALESCE(parent_id, ''), created_at FROM categories WHERE id = $1", id).
+		Scan(&category.Id, &category.Name, &category.ParentId, &category.Created_at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (repo *PostgresRepository) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	return repo.queryCategories(ctx, "SELECT id, name, COALESCE(parent_id, ''), created_at FROM categories ORDER BY name, id")
+}
+
+func (repo *PostgresRepository) UpdateCategory(ctx context.Context, category *models.Category) error {
+	_, err := repo.db.ExecContext(ctx, "UPDATE categories SET name = $1, parent_id = NULLIF($2, '') WHERE id = $3", category.Name, category.ParentId, category.Id)
+	return err
+}
+
+func (repo *PostgresRepository) DeleteCategory(ctx context.Context, id string) error {
+	var hasChildren bool
+	if err := repo.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)", id).Scan(&hasChildren); err != nil {
+		return err
+	}
+	if hasChildren {
+		return models.ErrCategoryHasChildren
+	}
+	// tax_rules restringe el borrado: quitar la categoría cambiaría en
+	// silencio los impuestos de sus productos.
+	_, err := repo.db.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return models.ErrCategoryHasTaxRules
+	}
+	return err
+}
+
+func (repo *PostgresRepository) SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_categories WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+	for _, categoryID := range categoryIDs {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO product_categories (product_id, category_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", productID, categoryID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (repo *PostgresRepository) ListProductCategories(ctx context.Context, productID string) ([]*models.Category, error) {
+	return repo.queryCategories(ctx, "SELECT c.id, c.name, COALESCE(c.parent_id, ''), c.created_at FROM categories c JOIN product_categories pc ON pc.category_id = c.id WHERE pc.product_id = $1 ORDER BY c.name, c.id", productID)
+}
+
+func (repo *PostgresRepository) queryCategories(ctx context.Context, query string, args ...any) ([]*models.Category, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	categories := []*models.Category{}
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.Id, &category.Name, &category.ParentId, &category.Created_at); err != nil {
+			return nil, err
+		}
+		categories = append(categories, &category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
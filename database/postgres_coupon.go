@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/lib/pq"
+)
+
+const selectCouponsQuery = `SELECT c.code, c.type, c.percent, c.amount, c.min_subtotal, c.starts_at, c.ends_at, c.max_uses, c.max_uses_per_user, c.product_ids, c.category_ids, c.created_at,
+	(SELECT COUNT(*) FROM coupon_redemptions r WHERE r.code = c.code)
+	FROM coupons c`
+
+func (repo *PostgresRepository) InsertCoupon(ctx context.Context, coupon *models.Coupon) error {
+	err := repo.db.QueryRowContext(ctx, "INSERT INTO coupons (code, type, percent, amount, min_subtotal, starts_at, ends_at, max_uses, max_uses_per_user, product_ids, category_ids) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING created_at",
+		coupon.Code, coupon.Type, coupon.Percent, coupon.Amount, coupon.MinSubtotal, coupon.Starts_at, coupon.Ends_at, coupon.MaxUses, coupon.MaxUsesPerUser, pq.Array(coupon.ProductIds), pq.Array(coupon.CategoryIds)).
+		Scan(&coupon.Created_at)
+	if isUniqueViolation(err) {
+		return models.ErrDuplicateCoupon
+	}
+	return err
+}
+
+func (repo *PostgresRepository) GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	coupons, err := repo.queryCoupons(ctx, selectCouponsQuery+" WHERE c.code = $1", code)
+	if err != nil {
+		return nil, err
+	}
+	if len(coupons) == 0 {
+		return nil, nil
+	}
+	return coupons[0], nil
+}
+
+func (repo *PostgresRepository) ListCoupons(ctx context.Context) ([]*models.Coupon, error) {
+	return repo.queryCoupons(ctx, selectCouponsQuery+" ORDER BY c.created_at DESC, c.code")
+}
+
+func (repo *PostgresRepository) UpdateCoupon(ctx context.Context, coupon *models.Coupon) error {
+	_, err := repo.db.ExecContext(ctx, "UPDATE coupons SET type = $1, percent = $2, amount = $3, min_subtotal = $4, starts_at = $5, ends_at = $6, max_uses = $7, max_uses_per_user = $8, product_ids = $9, category_ids = $10 WHERE code = $11",
+		coupon.Type, coupon.Percent, coupon.Amount, coupon.MinSubtotal, coupon.Starts_at, coupon.Ends_at, coupon.MaxUses, coupon.MaxUsesPerUser, pq.Array(coupon.ProductIds), pq.Array(coupon.CategoryIds), coupon.Code)
+	return err
+}
+
+func (repo *PostgresRepository) DeleteCoupon(ctx context.Context, code string) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM coupons WHERE code = $1", code)
+	if isForeignKeyViolation(err) {
+		return models.ErrCouponInUse
+	}
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrCouponNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresRepository) CountCouponUses(ctx context.Context, code string, userID string) (int, error) {
+	var uses int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM coupon_redemptions WHERE code = $1 AND user_id = $2", code, userID).Scan(&uses)
+	return uses, err
+}
+
+// SetCartCoupon crea el carrito si hace falta; un código vacío quita el cupón.
+func (repo *PostgresRepository) SetCartCoupon(ctx context.Context, userID string, code string) error {
+	_, err := repo.db.ExecContext(ctx, "INSERT INTO carts (user_id, coupon_code) VALUES ($1, NULLIF($2, '')) ON CONFLICT (user_id) DO UPDATE SET coupon_code = EXCLUDED.coupon_code, updated_at = NOW()", userID, code)
+	return err
+}
+
+// redeemCoupon bloquea la fila del cupón para que dos órdenes simultáneas no
+// pasen el límite de usos, vuelve a revisarlo y registra el canje.
+func redeemCoupon(ctx context.Context, tx *sql.Tx, order *models.Order) error {
+	var coupon models.Coupon
+	err := tx.QueryRowContext(ctx, "SELECT starts_at, ends_at, max_uses, max_uses_per_user FROM coupons WHERE code = $1 FOR UPDATE", order.CouponCode).
+		Scan(&coupon.Starts_at, &coupon.Ends_at, &coupon.MaxUses, &coupon.MaxUsesPerUser)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: coupon no longer exists", models.ErrInvalidCoupon)
+	}
+	if err != nil {
+		return err
+	}
+
+	var uses, userUses int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2) FROM coupon_redemptions WHERE code = $1", order.CouponCode, order.UserId).
+		Scan(&uses, &userUses)
+	if err != nil {
+		return err
+	}
+	if err := coupon.CheckAvailable(time.Now(), uses, userUses); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO coupon_redemptions (order_id, code, user_id, discount) VALUES ($1, $2, $3, $4)", order.Id, order.CouponCode, order.UserId, order.Discount)
+	return err
+}
+
+func (repo *PostgresRepository) queryCoupons(ctx context.Context, query string, args ...any) ([]*models.Coupon, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	coupons := []*models.Coupon{}
+	for rows.Next() {
+		var coupon models.Coupon
+		if err := rows.Scan(&coupon.Code, &coupon.Type, &coupon.Percent, &coupon.Amount, &coupon.MinSubtotal, &coupon.Starts_at, &coupon.Ends_at, &coupon.MaxUses, &coupon.MaxUsesPerUser, pq.Array(&coupon.ProductIds), pq.Array(&coupon.CategoryIds), &coupon.Created_at, &coupon.Uses); err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, &coupon)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// EachProduct recorre con el cursor de la base todos los productos que pasan
+// el filtro, sin cargarlos en memoria. Ignora Limit. Si fn devuelve error se
+// corta el recorrido y se devuelve ese error.
+func (repo *PostgresRepository) EachProduct(ctx context.Context, filter models.ProductFilter, fn func(*models.Products) error) error {
+	query, args := buildProductSelect(filter)
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	for rows.Next() {
+		var product models.Products
+		if err := rows.Scan(&product.Id, &product.Title, &product.Description, &product.ImageUrl, &product.Currency, priceIn(&product), &product.TaxClass, &product.WeightGrams, &product.LengthMm, &product.WidthMm, &product.HeightMm, &product.Created_at, &product.Updated_at, &product.UserId); err != nil {
+			return err
+		}
+		if err := fn(&product); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
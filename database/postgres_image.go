@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// InsertProductImage agrega la imagen al final de las del producto.
+func (repo *PostgresRepository) InsertProductImage(ctx context.Context, image *models.ProductImage) error {
+	return repo.db.QueryRowContext(ctx, `INSERT INTO product_images (id, product_id, position, url, thumbnail_url, content_type, size, blob_key, thumbnail_key)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $2), $3, $4, $5, $6, $7, $8)
+		RETURNING position, created_at`,
+		image.Id, image.ProductId, image.Url, image.ThumbnailUrl, image.ContentType, image.Size, image.Key, image.ThumbnailKey).
+		Scan(&image.Position, &image.Created_at)
+}
+
+func (repo *PostgresRepository) ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT id, product_id, position, url, thumbnail_url, content_type, size, blob_key, thumbnail_key, created_at FROM product_images WHERE product_id = $1 ORDER BY position, id", productID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	images := []*models.ProductImage{}
+	for rows.Next() {
+		var image models.ProductImage
+		if err := rows.Scan(&image.Id, &image.ProductId, &image.Position, &image.Url, &image.ThumbnailUrl, &image.ContentType, &image.Size, &image.Key, &image.ThumbnailKey, &image.Created_at); err != nil {
+			return nil, err
+		}
+		images = append(images, &image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (repo *PostgresRepository) DeleteProductImage(ctx context.Context, productID string, id string) error {
+	_, err := repo.db.ExecContext(ctx, "DELETE FROM product_images WHERE id = $1 AND product_id = $2", id, productID)
+	return err
+}
+
+// ReorderProductImages deja las imágenes en el orden de ids.
+func (repo *PostgresRepository) ReorderProductImages(ctx context.Context, productID string, ids []string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for position, id := range ids {
+		if _, err := tx.ExecContext(ctx, "UPDATE product_images SET position = $1 WHERE id = $2 AND product_id = $3", position, id, productID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
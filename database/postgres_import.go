@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// importBatchSize limita las filas por INSERT para no pasar el máximo de
+// parámetros de Postgres.
+const importBatchSize = 500
+
+// InsertProducts guarda todos los productos en lotes dentro de una sola
+// transacción: o entran todos o ninguno.
+func (repo *PostgresRepository) InsertProducts(ctx context.Context, products []*models.Products) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(products); start += importBatchSize {
+		batch := products[start:min(start+importBatchSize, len(products))]
+		values := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*12)
+		for i, product := range batch {
+			n := i * 12
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11, n+12))
+			args = append(args, product.Id, product.Title, product.Description, product.ImageUrl, product.Price, product.Currency, product.TaxClass, product.WeightGrams, product.LengthMm, product.WidthMm, product.HeightMm, product.UserId)
+		}
+		query := "INSERT INTO products (id, title, description, image_url, price, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, user_id) VALUES " + strings.Join(values, ", ")
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/lib/pq"
+)
+
+func (repo *PostgresRepository) GetInventory(ctx context.Context, productID string) (*models.Inventory, error) {
+	inventory := models.Inventory{ProductId: productID, Tracked: true}
+	err := repo.db.QueryRowContext(ctx, "SELECT stock, reserved, updated_at FROM inventory WHERE product_id = $1", productID).
+		Scan(&inventory.Stock, &inventory.Reserved, &inventory.Updated_at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inventory, nil
+}
+
+// ListInventories devuelve el inventario de cada producto con el stock de sus
+// variantes. Un producto con variantes y sin fila en inventory aparece sin
+// control de stock propio.
+func (repo *PostgresRepository) ListInventories(ctx context.Context, productIDs []string) ([]*models.Inventory, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT p.id, i.product_id IS NOT NULL, COALESCE(i.stock, 0), COALESCE(i.reserved, 0), COALESCE(i.updated_at, p.updated_at)
+		FROM products p LEFT JOIN inventory i ON i.product_id = p.id
+		WHERE p.id = ANY($1) AND (i.product_id IS NOT NULL OR EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id))`, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	inventories := []*models.Inventory{}
+	byProduct := map[string]*models.Inventory{}
+	for rows.Next() {
+		inventory := models.Inventory{Variants: []*models.VariantStock{}}
+		if err := rows.Scan(&inventory.ProductId, &inventory.Tracked, &inventory.Stock, &inventory.Reserved, &inventory.Updated_at); err != nil {
+			return nil, err
+		}
+		inventories = append(inventories, &inventory)
+		byProduct[inventory.ProductId] = &inventory
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	variants, err := repo.db.QueryContext(ctx, "SELECT product_id, id, sku, stock FROM product_variants WHERE product_id = ANY($1) ORDER BY created_at, id", pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := variants.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+	for variants.Next() {
+		var productID string
+		var variant models.VariantStock
+		if err := variants.Scan(&productID, &variant.Id, &variant.Sku, &variant.Stock); err != nil {
+			return nil, err
+		}
+		if inventory, ok := byProduct[productID]; ok {
+			inventory.Variants = append(inventory.Variants, &variant)
+		}
+	}
+	if err := variants.Err(); err != nil {
+		return nil, err
+	}
+	return inventories, nil
+}
+
+// GetCatalogVersion suma a los updated_at la hora del último borrado, que
+// mantienen los triggers sobre catalog_changes.
+func (repo *PostgresRepository) GetCatalogVersion(ctx context.Context) (*models.CatalogVersion, error) {
+	var version models.CatalogVersion
+	err := repo.db.QueryRowContext(ctx, `SELECT GREATEST(
+			(SELECT MAX(updated_at) FROM products),
+			(SELECT MAX(updated_at)::timestamptz FROM inventory),
+			(SELECT MAX(updated_at)::timestamptz FROM product_variants),
+			(SELECT deleted_at FROM catalog_changes)),
+		(SELECT COUNT(*) FROM products)`).
+		Scan(&version.Updated_at, &version.Products)
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// SetInventory fija las unidades disponibles del producto; con stock nil deja
+// de controlarse el inventario.
+func (repo *PostgresRepository) SetInventory(ctx context.Context, productID string, stock *int) error {
+	if stock == nil {
+		_, err := repo.db.ExecContext(ctx, "DELETE FROM inventory WHERE product_id = $1", productID)
+		return err
+	}
+	_, err := repo.db.ExecContext(ctx, "INSERT INTO inventory (product_id, stock) VALUES ($1, $2) ON CONFLICT (product_id) DO UPDATE SET stock = EXCLUDED.stock, updated_at = NOW()", productID, *stock)
+	return err
+}
+
+// ExpireReservations cancela las órdenes pendientes cuyas reservas vencieron,
+// devolviendo el stock, y responde cuántas canceló.
+func (repo *PostgresRepository) ExpireReservations(ctx context.Context, now time.Time) (int, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT DISTINCT r.order_id FROM stock_reservations r JOIN orders o ON o.id = r.order_id WHERE r.status = $1 AND r.expires_at <= $2 AND o.status = $3",
+		models.ReservationActive, now, models.OrderPending)
+	if err != nil {
+		return 0, err
+	}
+	orderIDs := []string{}
+	for rows.Next() {
+		var orderID string
+		if err := rows.Scan(&orderID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		orderIDs = append(orderIDs, orderID)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+	if err := rows.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	expired := 0
+	for _, orderID := range orderIDs {
+		err := repo.UpdateOrderStatus(ctx, orderID, models.OrderPending, models.OrderCancelled)
+		if err == models.ErrInvalidOrderTransition {
+			// Se pagó o canceló mientras tanto.
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// reserveStock aparta el stock de cada item: el de la variante si el item la
+// tiene, y si no el del inventario del producto cuando se controla. Bloquea
+// las filas en orden de (producto, variante) para que dos compras concurrentes
+// no vendan la misma unidad ni se bloqueen entre sí.
+func reserveStock(ctx context.Context, tx *sql.Tx, order *models.Order) error {
+	items := slices.Clone(order.Items)
+	slices.SortFunc(items, func(a, b *models.OrderItem) int {
+		return cmp.Or(cmp.Compare(a.ProductId, b.ProductId), cmp.Compare(a.VariantId, b.VariantId))
+	})
+
+	expires := time.Now().Add(models.ReservationTTL)
+	for _, item := range items {
+		reserved, err := reserveItemStock(ctx, tx, item)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO stock_reservations (order_id, product_id, variant_id, quantity, expires_at) VALUES ($1, $2, $3, $4, $5)", order.Id, item.ProductId, item.VariantId, item.Quantity, expires); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reserveItemStock descuenta el stock de un item y dice si llevaba control de
+// stock. Una variante que ya no existe cuenta como agotada.
+func reserveItemStock(ctx context.Context, tx *sql.Tx, item *models.OrderItem) (bool, error) {
+	var stock int
+	if item.VariantId != "" {
+		err := tx.QueryRowContext(ctx, "SELECT stock FROM product_variants WHERE id = $1 AND product_id = $2 FOR UPDATE", item.VariantId, item.ProductId).Scan(&stock)
+		if err != nil && err != sql.ErrNoRows {
+			return false, err
+		}
+		if err == sql.ErrNoRows || stock < item.Quantity {
+			return false, fmt.Errorf("%w: %s %s", models.ErrOutOfStock, item.Title, item.Sku)
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE product_variants SET stock = stock - $1, updated_at = NOW() WHERE id = $2", item.Quantity, item.VariantId)
+		return err == nil, err
+	}
+
+	err := tx.QueryRowContext(ctx, "SELECT stock FROM inventory WHERE product_id = $1 FOR UPDATE", item.ProductId).Scan(&stock)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if stock < item.Quantity {
+		return false, fmt.Errorf("%w: %s", models.ErrOutOfStock, item.Title)
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE inventory SET stock = stock - $1, reserved = reserved + $1, updated_at = NOW() WHERE product_id = $2", item.Quantity, item.ProductId)
+	return err == nil, err
+}
+
+// settleReservations confirma las reservas al pagar y las libera al cancelar,
+// devolviendo el stock también si la orden ya estaba pagada. Las variantes no
+// llevan cuenta de reservadas; al cancelar solo recuperan el stock.
+func settleReservations(ctx context.Context, tx *sql.Tx, orderID string, status models.OrderStatus) error {
+	var statements []string
+	switch status {
+	case models.OrderPaid:
+		statements = []string{
+			"UPDATE inventory i SET reserved = GREATEST(i.reserved - r.quantity, 0), updated_at = NOW() FROM stock_reservations r WHERE r.order_id = $1 AND r.status = 'active' AND r.variant_id = '' AND i.product_id = r.product_id",
+			"UPDATE stock_reservations SET status = 'committed' WHERE order_id = $1 AND status = 'active'",
+		}
+	case models.OrderCancelled:
+		statements = []string{
+			"UPDATE inventory i SET stock = i.stock + r.quantity, reserved = GREATEST(i.reserved - r.quantity, 0), updated_at = NOW() FROM stock_reservations r WHERE r.order_id = $1 AND r.status = 'active' AND r.variant_id = '' AND i.product_id = r.product_id",
+			"UPDATE inventory i SET stock = i.stock + r.quantity, updated_at = NOW() FROM stock_reservations r WHERE r.order_id = $1 AND r.status = 'committed' AND r.variant_id = '' AND i.product_id = r.product_id",
+			"UPDATE product_variants v SET stock = v.stock + r.quantity, updated_at = NOW() FROM stock_reservations r WHERE r.order_id = $1 AND r.status IN ('active', 'committed') AND v.id = r.variant_id",
+			"UPDATE stock_reservations SET status = 'released' WHERE order_id = $1 AND status IN ('active', 'committed')",
+		}
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, orderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+const selectOrdersQuery = "SELECT o.id, o.user_id, o.status, COALESCE(o.coupon_code, ''), o.discount, o.free_shipping, COALESCE(o.tax_region, ''), o.tax, o.shipping_method_id, COALESCE(o.shipping_name, ''), COALESCE(o.shipping_type, ''), o.shipping_cost, o.shipping_address, o.total, o.created_at, o.updated_at, i.product_id, i.variant_id, i.sku, i.title, i.price, i.quantity, i.tax_rule_id, COALESCE(i.tax_rate_bps, 0), COALESCE(i.tax_inclusive, FALSE), i.tax_amount FROM orders o JOIN order_items i ON i.order_id = o.id"
+
+// InsertOrder guarda la orden con sus items, reserva el stock, canjea el cupón
+// y quita del carrito lo que entró en la orden, todo en la misma transacción.
+func (repo *PostgresRepository) InsertOrder(ctx context.Context, order *models.Order) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var methodID, methodName, methodType, address any
+	shippingCost := models.NewMoney(0, models.DefaultCurrency)
+	if order.Shipping != nil {
+		methodID, methodName, methodType, shippingCost = order.Shipping.MethodId, order.Shipping.Name, order.Shipping.Type, order.Shipping.Cost
+	}
+	if order.ShippingAddress != nil {
+		data, err := json.Marshal(order.ShippingAddress)
+		if err != nil {
+			return err
+		}
+		address = string(data)
+	}
+	err = tx.QueryRowContext(ctx, "INSERT INTO orders (id, user_id, status, coupon_code, discount, free_shipping, tax_region, tax, shipping_method_id, shipping_name, shipping_type, shipping_cost, shipping_address, total) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), $8, $9, $10, $11, $12, $13, $14) RETURNING created_at, updated_at",
+		order.Id, order.UserId, order.Status, order.CouponCode, order.Discount, order.FreeShipping, order.TaxRegion, order.Tax, methodID, methodName, methodType, shippingCost, address, order.Total).
+		Scan(&order.Created_at, &order.Updated_at)
+	if err != nil {
+		return err
+	}
+	for _, item := range order.Items {
+		var ruleID, rateBps, inclusive, amount any
+		if item.Tax != nil {
+			ruleID, rateBps, inclusive, amount = item.Tax.RuleId, item.Tax.RateBps, item.Tax.Inclusive, item.Tax.Amount
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO order_items (order_id, product_id, variant_id, sku, title, price, quantity, tax_rule_id, tax_rate_bps, tax_inclusive, tax_amount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)", order.Id, item.ProductId, item.VariantId, item.Sku, item.Title, item.Price, item.Quantity, ruleID, rateBps, inclusive, amount)
+		if err != nil {
+			return err
+		}
+	}
+	if err := reserveStock(ctx, tx, order); err != nil {
+		return err
+	}
+	if order.CouponCode != "" {
+		if err := redeemCoupon(ctx, tx, order); err != nil {
+			return err
+		}
+	}
+	if err := removeOrderedCartItems(ctx, tx, order); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// removeOrderedCartItems descuenta del carrito las cantidades de la orden. Lo
+// que el usuario agregó después de leer el carrito se queda en él.
+func removeOrderedCartItems(ctx context.Context, tx *sql.Tx, order *models.Order) error {
+	for _, item := range order.Items {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM cart_items WHERE user_id = $1 AND product_id = $2 AND variant_id = $3 AND quantity <= $4", order.UserId, item.ProductId, item.VariantId, item.Quantity); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE cart_items SET quantity = quantity - $1 WHERE user_id = $2 AND product_id = $3 AND variant_id = $4", item.Quantity, order.UserId, item.ProductId, item.VariantId); err != nil {
+			return err
+		}
+	}
+	if order.CouponCode != "" {
+		if _, err := tx.ExecContext(ctx, "UPDATE carts SET coupon_code = NULL WHERE user_id = $1 AND coupon_code = $2", order.UserId, order.CouponCode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *PostgresRepository) GetOrderById(ctx context.Context, id string) (*models.Order, error) {
+	orders, err := repo.queryOrders(ctx, selectOrdersQuery+" WHERE o.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	return orders[0], nil
+}
+
+func (repo *PostgresRepository) ListOrders(ctx context.Context, userID string) ([]*models.Order, error) {
+	return repo.queryOrders(ctx, selectOrdersQuery+" WHERE o.user_id = $1 ORDER BY o.created_at DESC, o.id", userID)
+}
+
+// UpdateOrderStatus solo cambia el estado si la orden sigue en from, así dos
+// transiciones concurrentes no se pisan. Al pagar o cancelar también confirma
+// o libera las reservas de stock de la orden; al cancelar el cupón vuelve a
+// quedar disponible.
+func (repo *PostgresRepository) UpdateOrderStatus(ctx context.Context, id string, from models.OrderStatus, to models.OrderStatus) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3", to, id, from)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrInvalidOrderTransition
+	}
+	if err := settleReservations(ctx, tx, id, to); err != nil {
+		return err
+	}
+	if to == models.OrderCancelled {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM coupon_redemptions WHERE order_id = $1", id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (repo *PostgresRepository) queryOrders(ctx context.Context, query string, args ...any) ([]*models.Order, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	orders := []*models.Order{}
+	var current *models.Order
+	for rows.Next() {
+		var order models.Order
+		var item models.OrderItem
+		var tax models.TaxLine
+		var ruleID sql.NullString
+		var shipping models.ShippingQuote
+		var methodID sql.NullString
+		var address []byte
+		if err := rows.Scan(&order.Id, &order.UserId, &order.Status, &order.CouponCode, &order.Discount, &order.FreeShipping, &order.TaxRegion, &order.Tax, &methodID, &shipping.Name, &shipping.Type, &shipping.Cost, &address, &order.Total, &order.Created_at, &order.Updated_at, &item.ProductId, &item.VariantId, &item.Sku, &item.Title, &item.Price, &item.Quantity, &ruleID, &tax.RateBps, &tax.Inclusive, &tax.Amount); err != nil {
+			return nil, err
+		}
+		if ruleID.Valid {
+			tax.RuleId = ruleID.String
+			item.Tax = &tax
+		}
+		if current == nil || current.Id != order.Id {
+			if methodID.Valid {
+				shipping.MethodId = methodID.String
+				order.Shipping = &shipping
+			}
+			if address != nil {
+				order.ShippingAddress = &models.Address{}
+				if err := json.Unmarshal(address, order.ShippingAddress); err != nil {
+					return nil, err
+				}
+			}
+			current = &order
+			orders = append(orders, current)
+		}
+		item.Subtotal = item.Price.Mul(int64(item.Quantity))
+		current.Items = append(current.Items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
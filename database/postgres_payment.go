@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+const selectPaymentsQuery = "SELECT id, order_id, user_id, provider, COALESCE(reference, ''), amount, currency, status, COALESCE(error, ''), created_at, updated_at FROM payments"
+
+func (repo *PostgresRepository) InsertPayment(ctx context.Context, payment *models.Payment) error {
+	return repo.db.QueryRowContext(ctx, "INSERT INTO payments (id, order_id, user_id, provider, reference, amount, currency, status, error) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, '')) RETURNING created_at, updated_at",
+		payment.Id, payment.OrderId, payment.UserId, payment.Provider, payment.Reference, payment.Amount, payment.Currency, payment.Status, payment.Error).
+		Scan(&payment.Created_at, &payment.Updated_at)
+}
+
+func (repo *PostgresRepository) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	return repo.db.QueryRowContext(ctx, "UPDATE payments SET reference = NULLIF($1, ''), status = $2, error = NULLIF($3, ''), updated_at = NOW() WHERE id = $4 RETURNING updated_at",
+		payment.Reference, payment.Status, payment.Error, payment.Id).
+		Scan(&payment.Updated_at)
+}
+
+func (repo *PostgresRepository) GetPaymentByReference(ctx context.Context, reference string) (*models.Payment, error) {
+	payments, err := repo.queryPayments(ctx, selectPaymentsQuery+" WHERE reference = $1", reference)
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) == 0 {
+		return nil, nil
+	}
+	return payments[0], nil
+}
+
+func (repo *PostgresRepository) ListPayments(ctx context.Context, orderID string) ([]*models.Payment, error) {
+	return repo.queryPayments(ctx, selectPaymentsQuery+" WHERE order_id = $1 ORDER BY created_at", orderID)
+}
+
+func (repo *PostgresRepository) queryPayments(ctx context.Context, query string, args ...any) ([]*models.Payment, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	payments := []*models.Payment{}
+	for rows.Next() {
+		var payment models.Payment
+		if err := rows.Scan(&payment.Id, &payment.OrderId, &payment.UserId, &payment.Provider, &payment.Reference, &payment.Amount, &payment.Currency, &payment.Status, &payment.Error, &payment.Created_at, &payment.Updated_at); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
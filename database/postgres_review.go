@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+const reviewPageSize = 10
+
+func (repo *PostgresRepository) InsertReview(ctx context.Context, review *models.Review) error {
+	err := repo.db.QueryRowContext(ctx, "INSERT INTO reviews (id, product_id, user_id, rating, title, body) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at",
+		review.Id, review.ProductId, review.UserId, review.Rating, review.Title, review.Body).
+		Scan(&review.Created_at)
+	if isUniqueViolation(err) {
+		return models.ErrDuplicateReview
+	}
+	return err
+}
+
+func (repo *PostgresRepository) ListReviews(ctx context.Context, productID string, page uint64) ([]*models.Review, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT id, product_id, user_id, rating, title, body, created_at FROM reviews WHERE product_id = $1 ORDER BY created_at DESC, id LIMIT $2 OFFSET $3",
+		productID, reviewPageSize, page*reviewPageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	reviews := []*models.Review{}
+	for rows.Next() {
+		var review models.Review
+		if err := rows.Scan(&review.Id, &review.ProductId, &review.UserId, &review.Rating, &review.Title, &review.Body, &review.Created_at); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, &review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (repo *PostgresRepository) GetReviewSummary(ctx context.Context, productID string) (*models.ReviewSummary, error) {
+	var summary models.ReviewSummary
+	err := repo.db.QueryRowContext(ctx, "SELECT COALESCE(ROUND(AVG(rating), 2), 0), COUNT(*) FROM reviews WHERE product_id = $1", productID).
+		Scan(&summary.AverageRating, &summary.ReviewCount)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+func (repo *PostgresRepository) HasDeliveredOrder(ctx context.Context, userID string, productID string) (bool, error) {
+	var delivered bool
+	err := repo.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM orders o JOIN order_items i ON i.order_id = o.id WHERE o.user_id = $1 AND i.product_id = $2 AND o.status = $3)",
+		userID, productID, models.OrderDelivered).
+		Scan(&delivered)
+	return delivered, err
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// ts_headline marca las coincidencias con estos separadores, que no son HTML;
+// el fragmento se escapa en Go y después se cambian por <mark>.
+const (
+	headlineStart   = "\x01"
+	headlineStop    = "\x02"
+	headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxWords=35, MinWords=15"
+)
+
+var headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// SearchProducts usa la columna search_vector (título con peso A, descripción
+// con peso B) que mantiene el trigger products_search_vector_update. Cada
+// palabra se busca como prefijo, igual que en el repositorio en memoria. La
+// paginación es por keyset sobre (rank, id).
+func (repo *PostgresRepository) SearchProducts(ctx context.Context, search models.ProductSearch) (*models.ProductSearchPage, error) {
+	terms := tokenize(search.Query)
+	if len(terms) == 0 {
+		return models.NewProductSearchPage(search, []*models.ProductSearchResult{}), nil
+	}
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	args := []any{strings.Join(terms, " & "), headlineStart, headlineStop, headlineOptions}
+	after := ""
+	if search.After != nil {
+		args = append(args, search.After.Rank, search.After.Id)
+		after = fmt.Sprintf(" WHERE rank < $%[1]d::real OR (rank = $%[1]d::real AND id > $%[2]d)", len(args)-1, len(args))
+	}
+	args = append(args, search.Limit+1)
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT id, title, COALESCE(description, ''), COALESCE(image_url, ''), currency, price, tax_class, weight_grams, length_mm, width_mm, height_mm, created_at, updated_at, user_id, rank,
+		ts_headline('simple', replace(replace(title || ' ' || COALESCE(description, ''), $2, ''), $3, ''), q, $4)
+		FROM (
+			SELECT p.*, q, ts_rank_cd(p.search_vector, q) AS rank
+			FROM products p, to_tsquery('simple', $1) q
+			WHERE p.search_vector @@ q
+		) matches`+after+fmt.Sprintf(`
+		ORDER BY rank DESC, id
+		LIMIT $%d`, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	results := []*models.ProductSearchResult{}
+	for rows.Next() {
+		var result models.ProductSearchResult
+		if err := rows.Scan(&result.Id, &result.Title, &result.Description, &result.ImageUrl, &result.Currency, priceIn(&result.Products), &result.TaxClass, &result.WeightGrams, &result.LengthMm, &result.WidthMm, &result.HeightMm, &result.Created_at, &result.Updated_at, &result.UserId, &result.Rank, &result.Snippet); err != nil {
+			return nil, err
+		}
+		result.Snippet = headlineMarks.Replace(html.EscapeString(result.Snippet))
+		results = append(results, &result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return models.NewProductSearchPage(search, results), nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/lib/pq"
+)
+
+const selectShippingMethodsQuery = "SELECT id, name, type, regions, price, per_kg, free_over, max_weight_grams, created_at FROM shipping_methods"
+
+func (repo *PostgresRepository) InsertShippingMethod(ctx context.Context, method *models.ShippingMethod) error {
+	return repo.db.QueryRowContext(ctx, "INSERT INTO shipping_methods (id, name, type, regions, price, per_kg, free_over, max_weight_grams) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at",
+		method.Id, method.Name, method.Type, pq.Array(method.Regions), method.Price, method.PerKg, method.FreeOver, method.MaxWeightGrams).
+		Scan(&method.Created_at)
+}
+
+func (repo *PostgresRepository) GetShippingMethodById(ctx context.Context, id string) (*models.ShippingMethod, error) {
+	methods, err := repo.queryShippingMethods(ctx, selectShippingMethodsQuery+" WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) == 0 {
+		return nil, nil
+	}
+	return methods[0], nil
+}
+
+func (repo *PostgresRepository) ListShippingMethods(ctx context.Context) ([]*models.ShippingMethod, error) {
+	return repo.queryShippingMethods(ctx, selectShippingMethodsQuery+" ORDER BY created_at, id")
+}
+
+func (repo *PostgresRepository) UpdateShippingMethod(ctx context.Context, method *models.ShippingMethod) error {
+	_, err := repo.db.ExecContext(ctx, "UPDATE shipping_methods SET name = $1, type = $2, regions = $3, price = $4, per_kg = $5, free_over = $6, max_weight_grams = $7 WHERE id = $8",
+		method.Name, method.Type, pq.Array(method.Regions), method.Price, method.PerKg, method.FreeOver, method.MaxWeightGrams, method.Id)
+	return err
+}
+
+func (repo *PostgresRepository) DeleteShippingMethod(ctx context.Context, id string) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM shipping_methods WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrShippingMethodNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresRepository) queryShippingMethods(ctx context.Context, query string, args ...any) ([]*models.ShippingMethod, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	methods := []*models.ShippingMethod{}
+	for rows.Next() {
+		var method models.ShippingMethod
+		if err := rows.Scan(&method.Id, &method.Name, &method.Type, pq.Array(&method.Regions), &method.Price, &method.PerKg, &method.FreeOver, &method.MaxWeightGrams, &method.Created_at); err != nil {
+			return nil, err
+		}
+		methods = append(methods, &method)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
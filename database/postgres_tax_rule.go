@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+const selectTaxRulesQuery = "SELECT id, region, COALESCE(category_id, ''), tax_class, rate_bps, inclusive, created_at FROM tax_rules"
+
+func (repo *PostgresRepository) InsertTaxRule(ctx context.Context, rule *models.TaxRule) error {
+	return repo.db.QueryRowContext(ctx, "INSERT INTO tax_rules (id, region, category_id, tax_class, rate_bps, inclusive) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6) RETURNING created_at",
+		rule.Id, rule.Region, rule.CategoryId, rule.TaxClass, rule.RateBps, rule.Inclusive).
+		Scan(&rule.Created_at)
+}
+
+func (repo *PostgresRepository) GetTaxRuleById(ctx context.Context, id string) (*models.TaxRule, error) {
+	rules, err := repo.queryTaxRules(ctx, selectTaxRulesQuery+" WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return rules[0], nil
+}
+
+func (repo *PostgresRepository) ListTaxRules(ctx context.Context) ([]*models.TaxRule, error) {
+	return repo.queryTaxRules(ctx, selectTaxRulesQuery+" ORDER BY region, id")
+}
+
+func (repo *PostgresRepository) UpdateTaxRule(ctx context.Context, rule *models.TaxRule) error {
+	_, err := repo.db.ExecContext(ctx, "UPDATE tax_rules SET region = $1, category_id = NULLIF($2, ''), tax_class = $3, rate_bps = $4, inclusive = $5 WHERE id = $6",
+		rule.Region, rule.CategoryId, rule.TaxClass, rule.RateBps, rule.Inclusive, rule.Id)
+	return err
+}
+
+func (repo *PostgresRepository) DeleteTaxRule(ctx context.Context, id string) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM tax_rules WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrTaxRuleNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresRepository) queryTaxRules(ctx context.Context, query string, args ...any) ([]*models.TaxRule, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	rules := []*models.TaxRule{}
+	for rows.Next() {
+		var rule models.TaxRule
+		if err := rows.Scan(&rule.Id, &rule.Region, &rule.CategoryId, &rule.TaxClass, &rule.RateBps, &rule.Inclusive, &rule.Created_at); err != nil {
+			return nil, err
+		}
+		rules = append(rules, &rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func (repo *PostgresRepository) InsertRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return repo.db.QueryRowContext(ctx, "INSERT INTO refresh_tokens (hash, user_id, family_id, expires_at) VALUES ($1, $2, $3, $4) RETURNING created_at", token.Hash, token.UserId, token.FamilyId, token.Expires_at).
+		Scan(&token.Created_at)
+}
+
+func (repo *PostgresRepository) GetRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := repo.db.QueryRowContext(ctx, "SELECT hash, user_id, family_id, revoked, expires_at, created_at FROM refresh_tokens WHERE hash = $1", hash).
+		Scan(&token.Hash, &token.UserId, &token.FamilyId, &token.Revoked, &token.Expires_at, &token.Created_at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken falla con ErrRefreshTokenRevoked si otro proceso ya lo
+// revocó, así un mismo token no se puede rotar dos veces.
+func (repo *PostgresRepository) RevokeRefreshToken(ctx context.Context, hash string) error {
+	result, err := repo.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked = TRUE WHERE hash = $1 AND revoked = FALSE", hash)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrRefreshTokenRevoked
+	}
+	return nil
+}
+
+func (repo *PostgresRepository) RevokeRefreshTokens(ctx context.Context, userID string, familyID string) error {
+	_, err := repo.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND ($2 = '' OR family_id = $2) AND revoked = FALSE", userID, familyID)
+	return err
+}
+
+func (repo *PostgresRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if _, err := repo.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW()"); err != nil {
+		return err
+	}
+	_, err := repo.db.ExecContext(ctx, "INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING", jti, expiresAt)
+	return err
+}
+
+func (repo *PostgresRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := repo.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/lib/pq"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// El precio de la variante está en la moneda del producto.
+const selectVariantsQuery = "SELECT v.id, v.product_id, v.sku, p.currency, v.price, v.stock, v.options, v.created_at, v.updated_at FROM product_variants v JOIN products p ON p.id = v.product_id"
+
+func (repo *PostgresRepository) SetProductOptions(ctx context.Context, productID string, options []*models.ProductOption) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_options WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+	for position, option := range options {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO product_options (product_id, position, name, option_values) VALUES ($1, $2, $3, $4)", productID, position, option.Name, pq.Array(option.Values)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (repo *PostgresRepository) ListProductOptions(ctx context.Context, productID string) ([]*models.ProductOption, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT name, option_values FROM product_options WHERE product_id = $1 ORDER BY position", productID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	options := []*models.ProductOption{}
+	for rows.Next() {
+		var option models.ProductOption
+		if err := rows.Scan(&option.Name, pq.Array(&option.Values)); err != nil {
+			return nil, err
+		}
+		options = append(options, &option)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+func (repo *PostgresRepository) InsertVariant(ctx context.Context, variant *models.ProductVariant) error {
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return err
+	}
+	err = repo.db.QueryRowContext(ctx, "INSERT INTO product_variants (id, product_id, sku, price, stock, options) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at",
+		variant.Id, variant.ProductId, variant.Sku, variant.Price, variant.Stock, options).
+		Scan(&variant.Created_at, &variant.Updated_at)
+	return variantError(err)
+}
+
+func (repo *PostgresRepository) GetVariantById(ctx context.Context, id string) (*models.ProductVariant, error) {
+	variants, err := repo.queryVariants(ctx, selectVariantsQuery+" WHERE v.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	return variants[0], nil
+}
+
+func (repo *PostgresRepository) ListVariants(ctx context.Context, productID string) ([]*models.ProductVariant, error) {
+	return repo.queryVariants(ctx, selectVariantsQuery+" WHERE v.product_id = $1 ORDER BY v.created_at, v.id", productID)
+}
+
+func (repo *PostgresRepository) UpdateVariant(ctx context.Context, variant *models.ProductVariant) error {
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return err
+	}
+	_, err = repo.db.ExecContext(ctx, "UPDATE product_variants SET sku = $1, price = $2, stock = $3, options = $4, updated_at = NOW() WHERE id = $5 AND product_id = $6",
+		variant.Sku, variant.Price, variant.Stock, options, variant.Id, variant.ProductId)
+	return variantError(err)
+}
+
+func (repo *PostgresRepository) DeleteVariant(ctx context.Context, productID string, id string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM product_variants WHERE id = $1 AND product_id = $2", id, productID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM cart_items WHERE product_id = $1 AND variant_id = $2", productID, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (repo *PostgresRepository) queryVariants(ctx context.Context, query string, args ...any) ([]*models.ProductVariant, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	variants := []*models.ProductVariant{}
+	for rows.Next() {
+		var variant models.ProductVariant
+		var currency string
+		var price sql.NullString
+		var options []byte
+		if err := rows.Scan(&variant.Id, &variant.ProductId, &variant.Sku, &currency, &price, &variant.Stock, &options, &variant.Created_at, &variant.Updated_at); err != nil {
+			return nil, err
+		}
+		if price.Valid {
+			parsed, err := models.ParseMoney(price.String, currency)
+			if err != nil {
+				return nil, err
+			}
+			variant.Price = &parsed
+		}
+		if err := json.Unmarshal(options, &variant.Options); err != nil {
+			return nil, err
+		}
+		variants = append(variants, &variant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// variantError traduce la violación del índice único de sku.
+func variantError(err error) error {
+	if isUniqueViolation(err) {
+		return models.ErrDuplicateSku
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
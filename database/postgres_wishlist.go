@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// ListWishlist usa LEFT JOIN para seguir mostrando los productos borrados.
+func (repo *PostgresRepository) ListWishlist(ctx context.Context, userID string) ([]*models.WishlistItem, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT w.product_id, w.created_at, p.id, p.title, p.description, p.image_url, p.currency, p.price, p.created_at, p.updated_at, p.user_id
+		FROM wishlist_items w LEFT JOIN products p ON p.id = w.product_id
+		WHERE w.user_id = $1 ORDER BY w.created_at DESC, w.product_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			log.Fatal(cerr)
+		}
+	}()
+
+	items := []*models.WishlistItem{}
+	for rows.Next() {
+		var item models.WishlistItem
+		var id, title, description, imageUrl, currency, price, userId sql.NullString
+		var createdAt, updatedAt sql.NullTime
+		if err := rows.Scan(&item.ProductId, &item.Added_at, &id, &title, &description, &imageUrl, &currency, &price, &createdAt, &updatedAt, &userId); err != nil {
+			return nil, err
+		}
+		if id.Valid {
+			price, err := models.ParseMoney(price.String, currency.String)
+			if err != nil {
+				return nil, err
+			}
+			item.Product = &models.Products{
+				Id:          id.String,
+				Title:       title.String,
+				Description: description.String,
+				ImageUrl:    imageUrl.String,
+				Price:       price,
+				Currency:    price.Currency,
+				Created_at:  createdAt.Time,
+				Updated_at:  updatedAt.Time,
+				UserId:      userId.String,
+			}
+		} else {
+			item.Deleted = true
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AddWishlistItem ignora los productos que ya están en la lista.
+func (repo *PostgresRepository) AddWishlistItem(ctx context.Context, userID string, productID string) error {
+	_, err := repo.db.ExecContext(ctx, "INSERT INTO wishlist_items (user_id, product_id) VALUES ($1, $2) ON CONFLICT (user_id, product_id) DO NOTHING", userID, productID)
+	return err
+}
+
+func (repo *PostgresRepository) DeleteWishlistItem(ctx context.Context, userID string, productID string) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM wishlist_items WHERE user_id = $1 AND product_id = $2", userID, productID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrWishlistItemNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresRepository) ClearWishlist(ctx context.Context, userID string) error {
+	_, err := repo.db.ExecContext(ctx, "DELETE FROM wishlist_items WHERE user_id = $1", userID)
+	return err
+}
@@ -4,18 +4,410 @@ CREATE TABLE users (
   id VARCHAR(32) PRIMARY KEY,
   password VARCHAR(255) NOT NULL,
   email VARCHAR(255) NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'customer',
   created_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
 DROP TABLE IF EXISTS products;
 
+-- created_at y updated_at llevan zona horaria: los cursores y filtros envían
+-- instantes en UTC y deben compararse igual sea cual sea el TimeZone de la sesión.
+-- El precio está en la moneda del producto, con hasta tres decimales (KWD).
 CREATE TABLE products (
   id VARCHAR(32) PRIMARY KEY,
   title VARCHAR(225) NOT NULL,
   description TEXT,
   image_url TEXT,
+  price NUMERIC(11, 3) NOT NULL,
+  currency CHAR(3) NOT NULL DEFAULT 'USD',
+  tax_class VARCHAR(32) NOT NULL DEFAULT '',
+  weight_grams INTEGER NOT NULL DEFAULT 0 CHECK (weight_grams >= 0),
+  length_mm INTEGER NOT NULL DEFAULT 0 CHECK (length_mm >= 0),
+  width_mm INTEGER NOT NULL DEFAULT 0 CHECK (width_mm >= 0),
+  height_mm INTEGER NOT NULL DEFAULT 0 CHECK (height_mm >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  user_id VARCHAR(32) NOT NULL,
+  search_vector TSVECTOR,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX products_search_idx ON products USING GIN (search_vector);
+
+CREATE INDEX products_created_at_id_idx ON products (created_at, id);
+
+CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS trigger AS $$
+BEGIN
+  NEW.search_vector :=
+    setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'B');
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_search_vector_trigger
+  BEFORE INSERT OR UPDATE OF title, description ON products
+  FOR EACH ROW EXECUTE PROCEDURE products_search_vector_update();
+
+DROP TABLE IF EXISTS tax_rules;
+
+DROP TABLE IF EXISTS product_categories;
+
+DROP TABLE IF EXISTS categories;
+
+CREATE TABLE categories (
+  id VARCHAR(32) PRIMARY KEY,
+  name VARCHAR(120) NOT NULL,
+  parent_id VARCHAR(32),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (parent_id) REFERENCES categories(id)
+);
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
+
+CREATE TABLE product_categories (
+  product_id VARCHAR(32) NOT NULL,
+  category_id VARCHAR(32) NOT NULL,
+  PRIMARY KEY (product_id, category_id),
+  FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX product_categories_category_id_idx ON product_categories (category_id);
+
+-- rate_bps en puntos básicos (2100 = 21%). Una regla tiene categoría o clase de
+-- impuesto, nunca las dos; sin ninguna aplica a todos los productos.
+CREATE TABLE tax_rules (
+  id VARCHAR(32) PRIMARY KEY,
+  region VARCHAR(8) NOT NULL,
+  category_id VARCHAR(32),
+  tax_class VARCHAR(32) NOT NULL DEFAULT '',
+  rate_bps INTEGER NOT NULL CHECK (rate_bps BETWEEN 0 AND 10000),
+  inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CHECK (category_id IS NULL OR tax_class = ''),
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX tax_rules_region_idx ON tax_rules (region);
+
+DROP TABLE IF EXISTS product_variants;
+
+DROP TABLE IF EXISTS product_options;
+
+CREATE TABLE product_options (
+  product_id VARCHAR(32) NOT NULL,
+  position INTEGER NOT NULL,
+  name VARCHAR(60) NOT NULL,
+  option_values TEXT[] NOT NULL,
+  PRIMARY KEY (product_id, name),
+  FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE TABLE product_variants (
+  id VARCHAR(32) PRIMARY KEY,
+  product_id VARCHAR(32) NOT NULL,
+  sku VARCHAR(64) NOT NULL UNIQUE,
+  price NUMERIC(11, 3),
+  stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+  options JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX product_variants_product_id_idx ON product_variants (product_id);
+
+DROP TABLE IF EXISTS product_images;
+
+CREATE TABLE product_images (
+  id VARCHAR(32) PRIMARY KEY,
+  product_id VARCHAR(32) NOT NULL,
+  position INTEGER NOT NULL,
+  url TEXT NOT NULL,
+  thumbnail_url TEXT NOT NULL,
+  content_type VARCHAR(32) NOT NULL,
+  size BIGINT NOT NULL,
+  blob_key TEXT NOT NULL,
+  thumbnail_key TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX product_images_product_id_idx ON product_images (product_id, position);
+
+DROP TABLE IF EXISTS reviews;
+
+CREATE TABLE reviews (
+  id VARCHAR(32) PRIMARY KEY,
+  product_id VARCHAR(32) NOT NULL,
+  user_id VARCHAR(32) NOT NULL,
+  rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+  title VARCHAR(120) NOT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (product_id, user_id),
+  FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX reviews_product_id_idx ON reviews (product_id, created_at DESC);
+
+DROP TABLE IF EXISTS coupon_redemptions;
+
+DROP TABLE IF EXISTS coupons;
+
+-- Cero en max_uses o max_uses_per_user significa sin límite.
+CREATE TABLE coupons (
+  code VARCHAR(32) PRIMARY KEY,
+  type VARCHAR(16) NOT NULL,
+  percent INTEGER NOT NULL DEFAULT 0,
+  amount NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  min_subtotal NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  starts_at TIMESTAMP,
+  ends_at TIMESTAMP,
+  max_uses INTEGER NOT NULL DEFAULT 0,
+  max_uses_per_user INTEGER NOT NULL DEFAULT 0,
+  product_ids TEXT[] NOT NULL DEFAULT '{}',
+  category_ids TEXT[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+DROP TABLE IF EXISTS cart_items;
+
+DROP TABLE IF EXISTS carts;
+
+CREATE TABLE carts (
+  user_id VARCHAR(32) PRIMARY KEY,
+  coupon_code VARCHAR(32),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE cart_items (
+  user_id VARCHAR(32) NOT NULL,
+  product_id VARCHAR(32) NOT NULL,
+  -- Vacío si el producto no tiene variantes.
+  variant_id VARCHAR(32) NOT NULL DEFAULT '',
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, product_id, variant_id),
+  FOREIGN KEY (user_id) REFERENCES carts(user_id) ON DELETE CASCADE,
+  FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+DROP TABLE IF EXISTS wishlist_items;
+
+-- Sin clave foránea a products: si el producto se borra la entrada queda y se
+-- muestra como eliminada.
+CREATE TABLE wishlist_items (
+  user_id VARCHAR(32) NOT NULL,
+  product_id VARCHAR(32) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, product_id),
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+DROP TABLE IF EXISTS addresses;
+
+CREATE TABLE addresses (
+  id VARCHAR(32) PRIMARY KEY,
+  user_id VARCHAR(32) NOT NULL,
+  name VARCHAR(120) NOT NULL,
+  line1 VARCHAR(225) NOT NULL,
+  line2 VARCHAR(225) NOT NULL DEFAULT '',
+  city VARCHAR(120) NOT NULL,
+  region VARCHAR(3) NOT NULL DEFAULT '',
+  postal_code VARCHAR(16) NOT NULL DEFAULT '',
+  country CHAR(2) NOT NULL,
+  phone VARCHAR(32) NOT NULL DEFAULT '',
+  default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+  default_billing BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX addresses_user_id_idx ON addresses (user_id, created_at);
+
+-- A lo sumo una dirección por defecto de cada tipo por usuario.
+CREATE UNIQUE INDEX addresses_default_shipping_idx ON addresses (user_id) WHERE default_shipping;
+
+CREATE UNIQUE INDEX addresses_default_billing_idx ON addresses (user_id) WHERE default_billing;
+
+DROP TABLE IF EXISTS shipping_methods;
+
+-- Sin regiones el método sirve para cualquier destino; cero en
+-- max_weight_grams significa sin límite.
+CREATE TABLE shipping_methods (
+  id VARCHAR(32) PRIMARY KEY,
+  name VARCHAR(120) NOT NULL,
+  type VARCHAR(16) NOT NULL,
+  regions TEXT[] NOT NULL DEFAULT '{}',
+  price NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  per_kg NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  free_over NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  max_weight_grams INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+DROP TABLE IF EXISTS order_items;
+
+DROP TABLE IF EXISTS orders;
+
+CREATE TABLE orders (
+  id VARCHAR(32) PRIMARY KEY,
+  user_id VARCHAR(32) NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  coupon_code VARCHAR(32),
+  discount NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  free_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+  tax_region VARCHAR(8),
+  tax NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  -- Copia del método y la dirección de envío; sin clave foránea para que la
+  -- orden no cambie si se editan o borran.
+  shipping_method_id VARCHAR(32),
+  shipping_name VARCHAR(120),
+  shipping_type VARCHAR(16),
+  shipping_cost NUMERIC(10, 2) NOT NULL DEFAULT 0,
+  shipping_address JSONB,
+  total NUMERIC(10, 2) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX orders_user_id_idx ON orders (user_id, created_at DESC);
+
+-- Una fila por orden que usó el cupón; se borra si la orden se cancela. Un
+-- cupón con canjes no se puede borrar.
+CREATE TABLE coupon_redemptions (
+  order_id VARCHAR(32) PRIMARY KEY,
+  code VARCHAR(32) NOT NULL,
+  user_id VARCHAR(32) NOT NULL,
+  discount NUMERIC(10, 2) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+  FOREIGN KEY (code) REFERENCES coupons(code) ON DELETE RESTRICT
+);
+
+CREATE INDEX coupon_redemptions_code_idx ON coupon_redemptions (code, user_id);
+
+CREATE TABLE order_items (
+  order_id VARCHAR(32) NOT NULL,
+  product_id VARCHAR(32) NOT NULL,
+  variant_id VARCHAR(32) NOT NULL DEFAULT '',
+  sku VARCHAR(64) NOT NULL DEFAULT '',
+  title VARCHAR(225) NOT NULL,
   price NUMERIC(10, 2) NOT NULL,
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  -- Copia de la regla de impuesto aplicada; NULL si el item no pagó impuesto.
+  tax_rule_id VARCHAR(32),
+  tax_rate_bps INTEGER,
+  tax_inclusive BOOLEAN,
+  tax_amount NUMERIC(10, 2),
+  PRIMARY KEY (order_id, product_id, variant_id),
+  FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+DROP TABLE IF EXISTS stock_reservations;
+
+DROP TABLE IF EXISTS inventory;
+
+CREATE TABLE inventory (
+  product_id VARCHAR(32) PRIMARY KEY,
+  stock INTEGER NOT NULL CHECK (stock >= 0),
+  reserved INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+DROP TABLE IF EXISTS catalog_changes;
+
+-- Una sola fila con la hora del último borrado de un producto o de su
+-- inventario; un borrado no deja updated_at y el feed la necesita para que
+-- Last-Modified avance.
+CREATE TABLE catalog_changes (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO catalog_changes DEFAULT VALUES;
+
+CREATE OR REPLACE FUNCTION catalog_deleted() RETURNS trigger AS $$
+BEGIN
+  UPDATE catalog_changes SET deleted_at = NOW();
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_deleted_trigger
+  AFTER DELETE ON products
+  FOR EACH STATEMENT EXECUTE PROCEDURE catalog_deleted();
+
+CREATE TRIGGER inventory_deleted_trigger
+  AFTER DELETE ON inventory
+  FOR EACH STATEMENT EXECUTE PROCEDURE catalog_deleted();
+
+CREATE TRIGGER product_variants_deleted_trigger
+  AFTER DELETE ON product_variants
+  FOR EACH STATEMENT EXECUTE PROCEDURE catalog_deleted();
+
+-- Con variant_id la reserva descuenta product_variants.stock; sin ella, inventory.
+CREATE TABLE stock_reservations (
+  order_id VARCHAR(32) NOT NULL,
+  product_id VARCHAR(32) NOT NULL,
+  variant_id VARCHAR(32) NOT NULL DEFAULT '',
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  status VARCHAR(16) NOT NULL DEFAULT 'active',
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (order_id, product_id, variant_id),
+  FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX stock_reservations_active_idx ON stock_reservations (expires_at) WHERE status = 'active';
+
+DROP TABLE IF EXISTS payments;
+
+CREATE TABLE payments (
+  id VARCHAR(32) PRIMARY KEY,
+  order_id VARCHAR(32) NOT NULL,
+  user_id VARCHAR(32) NOT NULL,
+  provider VARCHAR(32) NOT NULL,
+  reference VARCHAR(64),
+  amount NUMERIC(10, 2) NOT NULL,
+  currency CHAR(3) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  error TEXT,
   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (order_id) REFERENCES orders(id),
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX payments_order_id_idx ON payments (order_id);
+
+CREATE UNIQUE INDEX payments_reference_idx ON payments (reference);
+
+DROP TABLE IF EXISTS refresh_tokens;
+
+CREATE TABLE refresh_tokens (
+  hash CHAR(64) PRIMARY KEY,
   user_id VARCHAR(32) NOT NULL,
+  family_id VARCHAR(32) NOT NULL,
+  revoked BOOLEAN NOT NULL DEFAULT FALSE,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
   FOREIGN KEY (user_id) REFERENCES users(id)
-);
\ No newline at end of file
+);
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id, family_id);
+
+DROP TABLE IF EXISTS revoked_tokens;
+
+CREATE TABLE revoked_tokens (
+  jti VARCHAR(32) PRIMARY KEY,
+  expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+type UpsertAddressRequest struct {
+	Name            string `json:"name"`
+	Line1           string `json:"line1"`
+	Line2           string `json:"line2"`
+	City            string `json:"city"`
+	Region          string `json:"region"`
+	PostalCode      string `json:"postal_code"`
+	Country         string `json:"country"`
+	Phone           string `json:"phone"`
+	DefaultShipping bool   `json:"default_shipping"`
+	DefaultBilling  bool   `json:"default_billing"`
+}
+
+func ListAddressesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		addresses, err := repository.ListAddresses(r.Context(), claims.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(addresses)
+	}
+}
+
+func GetAddressHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		address, ok := routeAddress(w, r, claims.UserId)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(address)
+	}
+}
+
+// InsertAddressHandler marca la dirección como de envío o de facturación por
+// defecto si lo pide o si el usuario todavía no tiene una.
+func InsertAddressHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = UpsertAddressRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		address := &models.Address{Id: id.String(), UserId: claims.UserId}
+		request.apply(address)
+		if err := address.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		shipping, billing, err := defaultAddresses(r.Context(), claims.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		address.DefaultShipping = address.DefaultShipping || shipping == nil
+		address.DefaultBilling = address.DefaultBilling || billing == nil
+
+		if err := repository.InsertAddress(r.Context(), address); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(address)
+	}
+}
+
+// UpdateAddressHandler reemplaza la dirección completa. Las órdenes ya hechas
+// guardan su propia copia y no cambian.
+func UpdateAddressHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = UpsertAddressRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		address, ok := routeAddress(w, r, claims.UserId)
+		if !ok {
+			return
+		}
+		request.apply(address)
+		if err := address.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err := repository.UpdateAddress(r.Context(), address)
+		if errors.Is(err, models.ErrAddressNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(address)
+	}
+}
+
+func DeleteAddressHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		params := mux.Vars(r)
+		err := repository.DeleteAddress(r.Context(), claims.UserId, params["id"])
+		if errors.Is(err, models.ErrAddressNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Delete address",
+		})
+	}
+}
+
+func (request *UpsertAddressRequest) apply(address *models.Address) {
+	address.Name = request.Name
+	address.Line1 = request.Line1
+	address.Line2 = request.Line2
+	address.City = request.City
+	address.Region = request.Region
+	address.PostalCode = request.PostalCode
+	address.Country = request.Country
+	address.Phone = request.Phone
+	address.DefaultShipping = request.DefaultShipping
+	address.DefaultBilling = request.DefaultBilling
+	address.Normalize()
+}
+
+func routeAddress(w http.ResponseWriter, r *http.Request, userID string) (*models.Address, bool) {
+	params := mux.Vars(r)
+	address, err := repository.GetAddressById(r.Context(), userID, params["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if address == nil {
+		http.Error(w, models.ErrAddressNotFound.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	return address, true
+}
+
+// defaultAddresses devuelve las direcciones de envío y de facturación por
+// defecto del usuario; cualquiera de las dos puede ser nil.
+func defaultAddresses(ctx context.Context, userID string) (*models.Address, *models.Address, error) {
+	addresses, err := repository.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var shipping, billing *models.Address
+	for _, address := range addresses {
+		if address.DefaultShipping {
+			shipping = address
+		}
+		if address.DefaultBilling {
+			billing = address
+		}
+	}
+	return shipping, billing, nil
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/middleware"
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/server"
+)
+
+// appClaims valida el token de la petición y devuelve sus claims. Cuando
+// devuelve false ya se respondió al cliente con el error.
+func appClaims(w http.ResponseWriter, s server.Server, r *http.Request) (*models.AppClaims, bool) {
+	token, err := middleware.TokenParseString(w, s, r)
+	if err != nil {
+		return nil, false
+	}
+	claims, ok := token.Claims.(*models.AppClaims)
+	if !ok || !token.Valid {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return nil, false
+	}
+	return claims, true
+}
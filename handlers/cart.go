@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+)
+
+// AddCartItemRequest lleva variant_id cuando el producto tiene variantes. Para
+// cambiar o quitar un item de una variante se usa ?variant_id= en la ruta.
+type AddCartItemRequest struct {
+	ProductId string `json:"product_id"`
+	VariantId string `json:"variant_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+func GetCartHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		writeCart(w, s, r, claims.UserId)
+	}
+}
+
+func AddCartItemHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = AddCartItemRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if request.Quantity == 0 {
+			request.Quantity = 1
+		}
+		if err := validateQuantity(request.Quantity); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		product, err := repository.GetProductById(r.Context(), request.ProductId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if product == nil {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		if !validateCartVariant(w, r, product.Id, request.VariantId) {
+			return
+		}
+
+		err = repository.AddCartItem(r.Context(), claims.UserId, product.Id, request.VariantId, request.Quantity)
+		if errors.Is(err, models.ErrCartItemQuantity) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeCart(w, s, r, claims.UserId)
+	}
+}
+
+func UpdateCartItemHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = UpdateCartItemRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateQuantity(request.Quantity); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		params := mux.Vars(r)
+		err := repository.UpdateCartItem(r.Context(), claims.UserId, params["productId"], r.URL.Query().Get("variant_id"), request.Quantity)
+		if errors.Is(err, models.ErrCartItemNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeCart(w, s, r, claims.UserId)
+	}
+}
+
+func DeleteCartItemHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		params := mux.Vars(r)
+		err := repository.DeleteCartItem(r.Context(), claims.UserId, params["productId"], r.URL.Query().Get("variant_id"))
+		if errors.Is(err, models.ErrCartItemNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeCart(w, s, r, claims.UserId)
+	}
+}
+
+// validateCartVariant exige elegir una variante del producto cuando las tiene,
+// porque el stock se lleva por variante.
+func validateCartVariant(w http.ResponseWriter, r *http.Request, productID string, variantID string) bool {
+	if variantID != "" {
+		variant, err := repository.GetVariantById(r.Context(), variantID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+		if variant == nil || variant.ProductId != productID {
+			http.Error(w, "variant not found", http.StatusNotFound)
+			return false
+		}
+		return true
+	}
+	variants, err := repository.ListVariants(r.Context(), productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if len(variants) > 0 {
+		http.Error(w, "variant_id is required for products with variants", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func validateQuantity(quantity int) error {
+	if quantity < 1 || quantity > models.MaxCartItemQuantity {
+		return models.ErrCartItemQuantity
+	}
+	return nil
+}
+
+func writeCart(w http.ResponseWriter, s server.Server, r *http.Request, userID string) {
+	region, err := taxRegion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cart, err := repository.GetCart(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := priceCart(r.Context(), s, cart, region); err != nil {
+		http.Error(w, err.Error(), currencyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cart)
+}
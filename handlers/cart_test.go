@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cristiangar0398/ShopAPI/currency"
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+func TestAddCartItemHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		already   int
+		variants  bool
+		body      string
+		want      int
+		wantItems int
+	}{
+		{name: "default quantity", body: `{"product_id":"%s"}`, want: http.StatusOK, wantItems: 1},
+		{name: "quantity", body: `{"product_id":"%s","quantity":3}`, want: http.StatusOK, wantItems: 3},
+		{name: "adds to the same line", already: 2, body: `{"product_id":"%s","quantity":3}`, want: http.StatusOK, wantItems: 5},
+		{name: "up to the limit", already: 60, body: `{"product_id":"%s","quantity":40}`, want: http.StatusOK, wantItems: 100},
+		{name: "over the limit in total", already: 60, body: `{"product_id":"%s","quantity":41}`, want: http.StatusBadRequest},
+		{name: "over the limit at once", body: `{"product_id":"%s","quantity":101}`, want: http.StatusBadRequest},
+		{name: "negative quantity", body: `{"product_id":"%s","quantity":-1}`, want: http.StatusBadRequest},
+		{name: "unknown product", body: `{"product_id":"missing"}`, want: http.StatusNotFound},
+		{name: "unknown variant", body: `{"product_id":"%s","variant_id":"missing"}`, want: http.StatusNotFound},
+		{name: "variant required", variants: true, body: `{"product_id":"%s"}`, want: http.StatusBadRequest},
+		{name: "malformed json", body: `{"product_id":`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			seller := ts.token(t, models.RoleSeller)
+			customer := ts.token(t, models.RoleCustomer)
+			productID := ts.insertProduct(t, seller, `{"title":"Lamp","price":10}`)
+			if tt.variants {
+				err := repository.InsertVariant(context.Background(), &models.ProductVariant{Id: "v1", ProductId: productID, Sku: "LAMP-RED", Stock: 5})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.already > 0 {
+				body := `{"product_id":"` + productID + `","quantity":` + strconv.Itoa(tt.already) + `}`
+				ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/items", customer, body)
+			}
+
+			body := strings.Replace(tt.body, "%s", productID, 1)
+			w := ts.do(http.MethodPost, "/cart/items", customer, body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var cart models.Cart
+			decodeBody(t, w, &cart)
+			if cart.TotalItems != tt.wantItems || len(cart.Items) != 1 {
+				t.Errorf("cart = %d units in %d lines, want %d in 1", cart.TotalItems, len(cart.Items), tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestCartWithRemovedRate(t *testing.T) {
+	ts := newTestServer(t)
+	rates := ts.server.Rates().(currency.Updater)
+	if err := rates.SetRates(context.Background(), &currency.Rates{Base: "USD", Rates: map[string]string{"JPY": "150"}}); err != nil {
+		t.Fatal(err)
+	}
+	customer := ts.token(t, models.RoleCustomer)
+	productID := ts.insertProduct(t, ts.token(t, models.RoleSeller), `{"title":"Teapot","price":1500,"currency":"JPY"}`)
+	ts.mustDo(t, http.StatusCreated, http.MethodPost, "/me/addresses", customer, testAddress)
+	ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/items", customer, `{"product_id":"`+productID+`"}`)
+
+	if err := rates.SetRates(context.Background(), &currency.Rates{Base: "USD", Rates: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	w := ts.mustDo(t, http.StatusConflict, http.MethodPost, "/orders", customer, "")
+	if body := w.Body.String(); !strings.Contains(body, `"Teapot" is priced in JPY`) {
+		t.Errorf("body = %q, want the item without a rate", body)
+	}
+}
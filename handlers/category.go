@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+type UpsertCategoryRequest struct {
+	Name     string `json:"name"`
+	ParentId string `json:"parent_id"`
+}
+
+type ProductCategoriesRequest struct {
+	CategoryIds []string `json:"category_ids"`
+}
+
+func ListCategoriesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categories, err := repository.ListCategories(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.CategoryTree(categories))
+	}
+}
+
+func GetCategoryByIdHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		categories, err := repository.ListCategories(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		models.CategoryTree(categories)
+
+		index := slices.IndexFunc(categories, func(c *models.Category) bool { return c.Id == params["id"] })
+		if index < 0 {
+			http.Error(w, "category not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(categories[index])
+	}
+}
+
+func InsertCategoryHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertCategoryRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		category := &models.Category{
+			Id:       id.String(),
+			Name:     strings.TrimSpace(request.Name),
+			ParentId: request.ParentId,
+		}
+		if status, err := validateCategory(r, category); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		if err := repository.InsertCategory(r.Context(), category); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(category)
+	}
+}
+
+func UpdateCategoryHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertCategoryRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params := mux.Vars(r)
+		category, err := repository.GetCategoryById(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if category == nil {
+			http.Error(w, "category not found", http.StatusNotFound)
+			return
+		}
+		category.Name = strings.TrimSpace(request.Name)
+		category.ParentId = request.ParentId
+		if status, err := validateCategory(r, category); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		if err := repository.UpdateCategory(r.Context(), category); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(category)
+	}
+}
+
+func DeleteCategoryHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		err := repository.DeleteCategory(r.Context(), params["id"])
+		if errors.Is(err, models.ErrCategoryHasChildren) || errors.Is(err, models.ErrCategoryHasTaxRules) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Delete category",
+		})
+	}
+}
+
+func ListProductCategoriesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		categories, err := repository.ListProductCategories(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(categories)
+	}
+}
+
+func SetProductCategoriesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = ProductCategoriesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		for _, categoryID := range request.CategoryIds {
+			category, err := repository.GetCategoryById(r.Context(), categoryID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if category == nil {
+				http.Error(w, "category "+categoryID+" not found", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if err := repository.SetProductCategories(r.Context(), product.Id, request.CategoryIds); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		categories, err := repository.ListProductCategories(r.Context(), product.Id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(categories)
+	}
+}
+
+// validateCategory revisa el nombre y que el padre exista y no sea la misma
+// categoría ni una de sus subcategorías.
+func validateCategory(r *http.Request, category *models.Category) (int, error) {
+	if category.Name == "" {
+		return http.StatusBadRequest, errors.New("name is required")
+	}
+	if category.ParentId == "" {
+		return 0, nil
+	}
+
+	categories, err := repository.ListCategories(r.Context())
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !slices.ContainsFunc(categories, func(c *models.Category) bool { return c.Id == category.ParentId }) {
+		return http.StatusBadRequest, errors.New("parent category not found")
+	}
+	if slices.Contains(models.DescendantIds(categories, category.Id), category.ParentId) {
+		return http.StatusBadRequest, models.ErrCategoryCycle
+	}
+	return 0, nil
+}
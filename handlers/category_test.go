@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+func TestDeleteCategoryHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		child    bool
+		taxRule  bool
+		want     int
+		wantGone bool
+	}{
+		{name: "unused category", want: http.StatusOK, wantGone: true},
+		{name: "with subcategories", child: true, want: http.StatusConflict},
+		{name: "with tax rules", taxRule: true, want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServer(t)
+			if err := repository.InsertCategory(ctx, &models.Category{Id: "books", Name: "Books"}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.child {
+				if err := repository.InsertCategory(ctx, &models.Category{Id: "novels", Name: "Novels", ParentId: "books"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.taxRule {
+				if err := repository.InsertTaxRule(ctx, &models.TaxRule{Id: "r1", Region: "US-CA", CategoryId: "books", RateBps: 500}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := ts.do(http.MethodDelete, "/categories/books", ts.token(t, models.RoleAdmin), "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			categories, err := repository.ListCategories(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gone := true
+			for _, category := range categories {
+				if category.Id == "books" {
+					gone = false
+				}
+			}
+			if gone != tt.wantGone {
+				t.Errorf("category deleted = %v after %d", gone, w.Code)
+			}
+			rules, err := repository.ListTaxRules(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.taxRule && len(rules) != 1 {
+				t.Errorf("tax rules = %d after %d, want the rule kept", len(rules), w.Code)
+			}
+		})
+	}
+}
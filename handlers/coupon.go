@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+)
+
+type UpsertCouponRequest struct {
+	Code           string            `json:"code"`
+	Type           models.CouponType `json:"type"`
+	Percent        int               `json:"percent"`
+	Amount         models.Money      `json:"amount"`
+	MinSubtotal    models.Money      `json:"min_subtotal"`
+	StartsAt       *time.Time        `json:"starts_at"`
+	EndsAt         *time.Time        `json:"ends_at"`
+	MaxUses        int               `json:"max_uses"`
+	MaxUsesPerUser int               `json:"max_uses_per_user"`
+	ProductIds     []string          `json:"product_ids"`
+	CategoryIds    []string          `json:"category_ids"`
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code"`
+}
+
+func ListCouponsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coupons, err := repository.ListCoupons(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(coupons)
+	}
+}
+
+func GetCouponHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coupon, ok := routeCoupon(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(coupon)
+	}
+}
+
+func InsertCouponHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertCouponRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		coupon := &models.Coupon{}
+		request.apply(coupon)
+		coupon.Code = models.NormalizeCouponCode(request.Code)
+		if err := coupon.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err := repository.InsertCoupon(r.Context(), coupon)
+		if errors.Is(err, models.ErrDuplicateCoupon) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(coupon)
+	}
+}
+
+// UpdateCouponHandler reemplaza todas las reglas del cupón; el código no se
+// puede cambiar porque las órdenes lo guardan.
+func UpdateCouponHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertCouponRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		coupon, ok := routeCoupon(w, r)
+		if !ok {
+			return
+		}
+		request.apply(coupon)
+		if err := coupon.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repository.UpdateCoupon(r.Context(), coupon); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(coupon)
+	}
+}
+
+func DeleteCouponHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		err := repository.DeleteCoupon(r.Context(), models.NormalizeCouponCode(params["code"]))
+		if errors.Is(err, models.ErrCouponNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, models.ErrCouponInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Delete coupon",
+		})
+	}
+}
+
+// ApplyCartCouponHandler solo guarda el cupón si aplica al carrito actual.
+func ApplyCartCouponHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = ApplyCouponRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		coupon, err := repository.GetCouponByCode(r.Context(), models.NormalizeCouponCode(request.Code))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if coupon == nil {
+			http.Error(w, models.ErrCouponNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		cart, err := repository.GetCart(r.Context(), claims.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := basePrices(r.Context(), s, cart); err != nil {
+			http.Error(w, err.Error(), currencyErrorStatus(err))
+			return
+		}
+		cart.CalculateTotals()
+		err = applyCoupon(r.Context(), cart, coupon)
+		if errors.Is(err, models.ErrInvalidCoupon) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := repository.SetCartCoupon(r.Context(), claims.UserId, coupon.Code); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeCart(w, s, r, claims.UserId)
+	}
+}
+
+func RemoveCartCouponHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		if err := repository.SetCartCoupon(r.Context(), claims.UserId, ""); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeCart(w, s, r, claims.UserId)
+	}
+}
+
+func (request *UpsertCouponRequest) apply(coupon *models.Coupon) {
+	coupon.Type = request.Type
+	coupon.Percent = request.Percent
+	coupon.Amount = request.Amount
+	coupon.MinSubtotal = request.MinSubtotal
+	coupon.Starts_at = request.StartsAt
+	coupon.Ends_at = request.EndsAt
+	coupon.MaxUses = request.MaxUses
+	coupon.MaxUsesPerUser = request.MaxUsesPerUser
+	coupon.ProductIds = append([]string{}, request.ProductIds...)
+	coupon.CategoryIds = append([]string{}, request.CategoryIds...)
+}
+
+func routeCoupon(w http.ResponseWriter, r *http.Request) (*models.Coupon, bool) {
+	params := mux.Vars(r)
+	coupon, err := repository.GetCouponByCode(r.Context(), models.NormalizeCouponCode(params["code"]))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if coupon == nil {
+		http.Error(w, models.ErrCouponNotFound.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	return coupon, true
+}
+
+// priceCart pasa los precios a la moneda base, calcula los totales, aplica el
+// cupón guardado en el carrito y
+// después los impuestos de la región. Si el cupón dejó de aplicar el carrito
+// queda sin descuento y CouponError dice por qué.
+func priceCart(ctx context.Context, s server.Server, cart *models.Cart, region string) error {
+	if err := basePrices(ctx, s, cart); err != nil {
+		return err
+	}
+	cart.CalculateTotals()
+	if cart.CouponCode != "" {
+		coupon, err := repository.GetCouponByCode(ctx, cart.CouponCode)
+		if err != nil {
+			return err
+		}
+		if coupon == nil {
+			err = fmt.Errorf("%w: coupon no longer exists", models.ErrInvalidCoupon)
+		} else {
+			err = applyCoupon(ctx, cart, coupon)
+		}
+		if errors.Is(err, models.ErrInvalidCoupon) {
+			cart.CouponError = err.Error()
+		} else if err != nil {
+			return err
+		}
+	}
+	return applyTaxes(ctx, s, cart, region)
+}
+
+func applyCoupon(ctx context.Context, cart *models.Cart, coupon *models.Coupon) error {
+	userUses, err := repository.CountCouponUses(ctx, coupon.Code, cart.UserId)
+	if err != nil {
+		return err
+	}
+	if err := coupon.CheckAvailable(time.Now(), coupon.Uses, userUses); err != nil {
+		return err
+	}
+	eligible, err := couponEligibleProducts(ctx, coupon, cart)
+	if err != nil {
+		return err
+	}
+	discount, err := coupon.Discount(cart, func(item *models.CartItem) bool { return eligible[item.ProductId] })
+	if err != nil {
+		return err
+	}
+	cart.CouponCode = coupon.Code
+	cart.ApplyDiscount(discount, coupon.Type == models.CouponFreeShipping)
+	return nil
+}
+
+// couponEligibleProducts marca los productos del carrito a los que aplica el
+// cupón. Una categoría incluye a todas sus subcategorías.
+func couponEligibleProducts(ctx context.Context, coupon *models.Coupon, cart *models.Cart) (map[string]bool, error) {
+	eligible := make(map[string]bool)
+	unrestricted := len(coupon.ProductIds) == 0 && len(coupon.CategoryIds) == 0
+	for _, item := range cart.Items {
+		eligible[item.ProductId] = unrestricted || slices.Contains(coupon.ProductIds, item.ProductId)
+	}
+	if len(coupon.CategoryIds) == 0 {
+		return eligible, nil
+	}
+
+	categories, err := repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool)
+	for _, id := range coupon.CategoryIds {
+		for _, descendant := range models.DescendantIds(categories, id) {
+			allowed[descendant] = true
+		}
+	}
+	for _, item := range cart.Items {
+		if eligible[item.ProductId] {
+			continue
+		}
+		productCategories, err := repository.ListProductCategories(ctx, item.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		eligible[item.ProductId] = slices.ContainsFunc(productCategories, func(c *models.Category) bool { return allowed[c.Id] })
+	}
+	return eligible, nil
+}
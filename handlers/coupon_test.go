@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cristiangar0398/ShopAPI/currency"
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+func TestDeleteCouponHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		redeemed bool
+		want     int
+	}{
+		{name: "unused coupon", code: "save10", want: http.StatusOK},
+		{name: "redeemed coupon", code: "SAVE10", redeemed: true, want: http.StatusConflict},
+		{name: "unknown coupon", code: "OTHER", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServer(t)
+			coupon := &models.Coupon{Code: "SAVE10", Type: models.CouponPercentage, Percent: 10}
+			if err := repository.InsertCoupon(ctx, coupon); err != nil {
+				t.Fatal(err)
+			}
+			if tt.redeemed {
+				order := &models.Order{
+					Id:         "o1",
+					UserId:     "u1",
+					Status:     models.OrderPending,
+					CouponCode: coupon.Code,
+					Discount:   models.NewMoney(100, models.DefaultCurrency),
+					Items:      []*models.OrderItem{{ProductId: "p1", Quantity: 1}},
+				}
+				if err := repository.InsertOrder(ctx, order); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := ts.do(http.MethodDelete, "/coupons/"+tt.code, ts.token(t, models.RoleAdmin), "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			stored, err := repository.GetCouponByCode(ctx, coupon.Code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted := stored == nil; deleted != (tt.want == http.StatusOK) {
+				t.Errorf("coupon deleted = %v after %d", deleted, w.Code)
+			}
+		})
+	}
+}
+
+func TestPayZeroTotalOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		coupon *models.Coupon
+	}{
+		{name: "full percentage", coupon: &models.Coupon{Code: "FREE", Type: models.CouponPercentage, Percent: 100}},
+		{name: "fixed over subtotal", coupon: &models.Coupon{Code: "FREE", Type: models.CouponFixed, Amount: models.NewMoney(5000, models.DefaultCurrency)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			if err := repository.InsertCoupon(context.Background(), tt.coupon); err != nil {
+				t.Fatal(err)
+			}
+			customer := ts.token(t, models.RoleCustomer)
+			productID := ts.insertProduct(t, ts.token(t, models.RoleSeller), `{"title":"Lamp","price":10}`)
+			ts.mustDo(t, http.StatusCreated, http.MethodPost, "/me/addresses", customer, testAddress)
+			ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/items", customer, `{"product_id":"`+productID+`"}`)
+			ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/coupon", customer, `{"code":"free"}`)
+			var order models.Order
+			decodeBody(t, ts.mustDo(t, http.StatusCreated, http.MethodPost, "/orders", customer, ""), &order)
+			if order.Total.Amount != 0 {
+				t.Fatalf("total = %s, want 0", order.Total)
+			}
+
+			var paid PayOrderResponse
+			decodeBody(t, ts.mustDo(t, http.StatusOK, http.MethodPost, "/orders/"+order.Id+"/pay", customer, `{"source":"tok_visa"}`), &paid)
+			if paid.Order.Status != models.OrderPaid || paid.Payment != nil {
+				t.Errorf("pay = order %s payment %+v, want paid without a payment", paid.Order.Status, paid.Payment)
+			}
+		})
+	}
+}
+
+func TestApplyCartCouponHandlerConvertsPrices(t *testing.T) {
+	ts := newTestServer(t)
+	err := ts.server.Rates().(currency.Updater).SetRates(context.Background(), &currency.Rates{
+		Base:  "USD",
+		Rates: map[string]string{"JPY": "150"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.InsertCoupon(context.Background(), &models.Coupon{Code: "SAVE10", Type: models.CouponPercentage, Percent: 10}); err != nil {
+		t.Fatal(err)
+	}
+	customer := ts.token(t, models.RoleCustomer)
+	productID := ts.insertProduct(t, ts.token(t, models.RoleSeller), `{"title":"Teapot","price":1500,"currency":"JPY"}`)
+	ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/items", customer, `{"product_id":"`+productID+`"}`)
+
+	var cart models.Cart
+	decodeBody(t, ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/coupon", customer, `{"code":"save10"}`), &cart)
+	if cart.CouponCode != "SAVE10" || cart.Discount != models.NewMoney(100, "USD") || cart.Total != models.NewMoney(900, "USD") {
+		t.Errorf("cart = coupon %q discount %s total %s, want SAVE10 1.00 9.00", cart.CouponCode, cart.Discount, cart.Total)
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/currency"
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/server"
+)
+
+const acceptCurrencyHeader = "Accept-Currency"
+
+// errPriceUnavailable marca un item cuya moneda perdió la tasa después de
+// crearse el producto: el carrito no se puede sumar hasta que la tasa vuelva o
+// se quite el item.
+var errPriceUnavailable = errors.New("price unavailable")
+
+func GetRatesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rates, err := s.Rates().Rates(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rates)
+	}
+}
+
+// SetRatesHandler reemplaza todas las tasas; solo funciona si el proveedor
+// configurado acepta cambios.
+func SetRatesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		updater, ok := s.Rates().(currency.Updater)
+		if !ok {
+			http.Error(w, currency.ErrReadOnly.Error(), http.StatusConflict)
+			return
+		}
+		var request = currency.Rates{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := updater.SetRates(r.Context(), &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rates, err := s.Rates().Rates(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rates)
+	}
+}
+
+// requestCurrency lee la moneda del parámetro currency o, si no viene, de la
+// primera entrada de Accept-Currency. Vacío significa la moneda base.
+func requestCurrency(r *http.Request) (string, error) {
+	value := r.URL.Query().Get("currency")
+	if value == "" {
+		value, _, _ = strings.Cut(r.Header.Get(acceptCurrencyHeader), ",")
+		value, _, _ = strings.Cut(value, ";")
+	}
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return "", nil
+	}
+	if !models.ValidCurrency(value) {
+		return "", errors.New("currency must be a three-letter ISO code")
+	}
+	return value, nil
+}
+
+// localizePrice convierte el precio a target; con target vacío lo deja en la
+// moneda base.
+func localizePrice(ctx context.Context, s server.Server, price *models.Money, target string) error {
+	if target == "" {
+		*price = models.NewMoney(price.Amount, price.Currency)
+		return nil
+	}
+	converted, err := currency.Convert(ctx, s.Rates(), *price, target)
+	if err != nil {
+		return err
+	}
+	*price = converted
+	return nil
+}
+
+func localizeProduct(ctx context.Context, s server.Server, product *models.Products, target string) error {
+	if err := localizePrice(ctx, s, &product.Price, target); err != nil {
+		return err
+	}
+	product.Currency = product.Price.Currency
+	return nil
+}
+
+// checkProductCurrency exige que la moneda de un producto tenga tasa hacia la
+// moneda base; sin ella el carrito no podría sumar el producto.
+func checkProductCurrency(ctx context.Context, s server.Server, code string) error {
+	if !models.ValidCurrency(code) {
+		return fmt.Errorf("%w: %q", currency.ErrUnknownCurrency, code)
+	}
+	if code == models.DefaultCurrency {
+		return nil
+	}
+	_, err := s.Rates().Rate(ctx, code, models.DefaultCurrency)
+	return err
+}
+
+// basePrices pasa el precio de cada item a la moneda base, la de los totales,
+// cupones, envíos y órdenes.
+func basePrices(ctx context.Context, s server.Server, cart *models.Cart) error {
+	for _, item := range cart.Items {
+		price, err := currency.Convert(ctx, s.Rates(), item.Price, models.DefaultCurrency)
+		if errors.Is(err, currency.ErrUnknownCurrency) {
+			return fmt.Errorf("%w: %q is priced in %s and there is no exchange rate to %s; remove it from the cart or try again later",
+				errPriceUnavailable, item.Title, models.NormalizeCurrency(item.Price.Currency), models.DefaultCurrency)
+		}
+		if err != nil {
+			return err
+		}
+		item.Price = price
+	}
+	return nil
+}
+
+func currencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, currency.ErrUnknownCurrency):
+		return http.StatusBadRequest
+	case errors.Is(err, errPriceUnavailable):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
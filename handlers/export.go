@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+)
+
+// exportFlushEvery es cada cuántas filas se envía lo escrito al cliente.
+const exportFlushEvery = 100
+
+var exportColumns = []string{"id", "title", "description", "image_url", "price", "currency", "tax_class", "weight_grams", "length_mm", "width_mm", "height_mm", "created_at", "user_id"}
+
+// ExportProductsHandler transmite el catálogo en csv o ndjson a medida que se
+// lee de la base. Acepta los mismos filtros que el listado salvo limit y
+// cursor; los vendedores solo exportan sus productos.
+func ExportProductsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "ndjson" {
+			http.Error(w, "format must be csv or ndjson", http.StatusBadRequest)
+			return
+		}
+		filter, err := productFilterParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.After = nil
+		if !claims.HasRole(models.RoleAdmin) {
+			filter.UserId = claims.UserId
+		}
+
+		var write func(*models.Products) error
+		var flush func()
+		if format == "csv" {
+			writer := csv.NewWriter(w)
+			write = func(product *models.Products) error {
+				return writer.Write([]string{
+					product.Id,
+					product.Title,
+					product.Description,
+					product.ImageUrl,
+					product.Price.String(),
+					models.NormalizeCurrency(product.Currency),
+					product.TaxClass,
+					strconv.Itoa(product.WeightGrams),
+					strconv.Itoa(product.LengthMm),
+					strconv.Itoa(product.WidthMm),
+					strconv.Itoa(product.HeightMm),
+					product.Created_at.UTC().Format(time.RFC3339),
+					product.UserId,
+				})
+			}
+			flush = writer.Flush
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="products.csv"`)
+			writer.Write(exportColumns)
+		} else {
+			encoder := json.NewEncoder(w)
+			write = func(product *models.Products) error {
+				return encoder.Encode(product)
+			}
+			flush = func() {}
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", `attachment; filename="products.ndjson"`)
+		}
+
+		flusher, _ := w.(http.Flusher)
+		rows := 0
+		err = repository.EachProduct(r.Context(), filter, func(product *models.Products) error {
+			if err := write(product); err != nil {
+				return err
+			}
+			rows++
+			if rows%exportFlushEvery == 0 {
+				flush()
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+			return nil
+		})
+		if err != nil && rows == 0 {
+			// Todavía no se envió nada (la cabecera del csv sigue en el buffer).
+			w.Header().Del("Content-Disposition")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		flush()
+		if err != nil {
+			// Ya se envió el estado 200; solo queda cortar la respuesta.
+			log.Printf("export: stopped after %d rows: %v", rows, err)
+		}
+	}
+}
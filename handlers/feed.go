@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+)
+
+const googleNamespace = "http://base.google.com/ns/1.0"
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Google  string     `xml:"xmlns:g,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Id           string `xml:"g:id"`
+	Title        string `xml:"title"`
+	Description  string `xml:"description"`
+	Link         string `xml:"link"`
+	ImageLink    string `xml:"g:image_link,omitempty"`
+	Price        string `xml:"g:price"`
+	Availability string `xml:"g:availability"`
+}
+
+// ProductFeedHandler publica el catálogo como RSS 2.0 con los campos de
+// Google Merchant. ETag y Last-Modified salen de la versión del catálogo (el
+// último cambio de un producto, de sus variantes o de su stock, borrados
+// incluidos), así un
+// crawler que ya tiene la versión actual recibe 304 sin que se arme el feed.
+func ProductFeedHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		site := siteUrl(s, r)
+		version, err := repository.GetCatalogVersion(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		lastModified := version.Updated_at.UTC().Truncate(time.Second)
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d", site, version.Updated_at.UnixNano(), version.Products)))
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		if notModified(r, etag, lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		feed, err := buildFeed(r, site)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var body bytes.Buffer
+		body.WriteString(xml.Header)
+		if err := xml.NewEncoder(&body).Encode(feed); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, "feed.xml", lastModified, bytes.NewReader(body.Bytes()))
+	}
+}
+
+// buildFeed recorre el catálogo por páginas y carga el stock de cada página en
+// una sola consulta.
+func buildFeed(r *http.Request, site string) (*rssFeed, error) {
+	feed := &rssFeed{
+		Version: "2.0",
+		Google:  googleNamespace,
+		Channel: rssChannel{
+			Title:       "ShopAPI",
+			Link:        site,
+			Description: "Product catalog",
+			Items:       []rssItem{},
+		},
+	}
+
+	filter := models.ProductFilter{Limit: models.MaxProductLimit, SortBy: models.SortByCreatedAt}
+	for {
+		page, err := repository.ListProducts(r.Context(), filter)
+		if err != nil {
+			return nil, err
+		}
+		ids := []string{}
+		for _, product := range page.Items {
+			ids = append(ids, product.Id)
+		}
+		inventories, err := repository.ListInventories(r.Context(), ids)
+		if err != nil {
+			return nil, err
+		}
+		stock := map[string]*models.Inventory{}
+		for _, inventory := range inventories {
+			stock[inventory.ProductId] = inventory
+		}
+
+		for _, product := range page.Items {
+			availability := "in_stock"
+			if inventory, ok := stock[product.Id]; ok && !inStock(inventory) {
+				availability = "out_of_stock"
+			}
+			feed.Channel.Items = append(feed.Channel.Items, rssItem{
+				Id:           product.Id,
+				Title:        product.Title,
+				Description:  product.Description,
+				Link:         site + "/product/" + product.Id,
+				ImageLink:    product.ImageUrl,
+				Price:        product.Price.String() + " " + models.NormalizeCurrency(product.Currency),
+				Availability: availability,
+			})
+		}
+		if !page.HasMore {
+			return feed, nil
+		}
+		filter.After = models.NewProductCursor(filter, page.Items[len(page.Items)-1])
+	}
+}
+
+// inStock mira las variantes si el producto las tiene, porque el stock se
+// descuenta de ellas; si no, el inventario del producto.
+func inStock(inventory *models.Inventory) bool {
+	if len(inventory.Variants) == 0 {
+		return !inventory.Tracked || inventory.Stock > 0
+	}
+	for _, variant := range inventory.Variants {
+		if variant.Stock > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified aplica If-None-Match y, si no viene, If-Modified-Since.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.After(since)
+}
+
+// siteUrl es la URL pública configurada o, si no hay, la del propio pedido.
+func siteUrl(s server.Server, r *http.Request) string {
+	if s.Config().PublicUrl != "" {
+		return s.Config().PublicUrl
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
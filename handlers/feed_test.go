@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+func TestProductFeedHandler(t *testing.T) {
+	ts := newTestServer(t)
+	seller := ts.token(t, models.RoleSeller)
+	ts.insertProduct(t, seller, `{"title":"Lamp","price":19.99}`)
+
+	first := ts.mustDo(t, http.StatusOK, http.MethodGet, "/feed.xml", "", "")
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("ETag %q Last-Modified %q", etag, lastModified)
+	}
+	if body := first.Body.String(); !strings.Contains(body, "<title>Lamp</title>") || !strings.Contains(body, "19.99 USD") {
+		t.Errorf("feed = %s", body)
+	}
+
+	later := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		name    string
+		method  string
+		headers []string
+		want    int
+	}{
+		{"same etag", http.MethodGet, []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"weak etag in a list", http.MethodGet, []string{"If-None-Match", `"other", W/` + etag}, http.StatusNotModified},
+		{"head with same etag", http.MethodHead, []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"other etag", http.MethodGet, []string{"If-None-Match", `"other"`}, http.StatusOK},
+		{"etag wins over date", http.MethodGet, []string{"If-None-Match", `"other"`, "If-Modified-Since", later}, http.StatusOK},
+		{"not modified since", http.MethodGet, []string{"If-Modified-Since", lastModified}, http.StatusNotModified},
+		{"modified since", http.MethodGet, []string{"If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := ts.do(tt.method, "/feed.xml", "", "", tt.headers...)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code == http.StatusNotModified && w.Body.Len() > 0 {
+				t.Errorf("304 with body %q", w.Body.String())
+			}
+		})
+	}
+
+	ts.insertProduct(t, seller, `{"title":"Desk","price":120}`)
+	w := ts.do(http.MethodGet, "/feed.xml", "", "", "If-None-Match", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("after a new product = %d with ETag %s, want 200 with a new ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestProductFeedVariantStock(t *testing.T) {
+	ts := newTestServer(t)
+	seller := ts.token(t, models.RoleSeller)
+	id := ts.insertProduct(t, seller, `{"title":"Shirt","price":25}`)
+	variant := &models.ProductVariant{Id: "variant-1", ProductId: id, Sku: "SHIRT-M", Options: map[string]string{"size": "M"}}
+	if err := repository.InsertVariant(context.Background(), variant); err != nil {
+		t.Fatal(err)
+	}
+
+	first := ts.mustDo(t, http.StatusOK, http.MethodGet, "/feed.xml", "", "")
+	if body := first.Body.String(); !strings.Contains(body, "<g:availability>out_of_stock</g:availability>") {
+		t.Errorf("feed with no variant stock = %s", body)
+	}
+
+	variant.Stock = 3
+	if err := repository.UpdateVariant(context.Background(), variant); err != nil {
+		t.Fatal(err)
+	}
+	w := ts.do(http.MethodGet, "/feed.xml", "", "", "If-None-Match", first.Header().Get("ETag"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("after a variant stock change = %d, want 200", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "<g:availability>in_stock</g:availability>") {
+		t.Errorf("feed with variant stock = %s", body)
+	}
+}
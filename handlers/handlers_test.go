@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/database"
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/segmentio/ksuid"
+)
+
+// testServer arma las rutas que prueban estos tests sobre el repositorio en
+// memoria. Los permisos por rol los pone main con middleware, así que aquí no
+// se revisan.
+type testServer struct {
+	server *server.Broker
+	router *mux.Router
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	s, err := server.NewServer(context.Background(), &server.Config{
+		Port:      ":0",
+		JWTSecret: "test-secret",
+		InMemory:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository.SetRepository(database.NewMemoryRepository())
+
+	r := mux.NewRouter()
+	r.HandleFunc("/me/addresses", InsertAddressHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/product", InsertProducttHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/product", ListProductHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/product/{id}", UpdateProducttHandler(s)).Methods(http.MethodPut)
+	r.HandleFunc("/product/{id}", DeleteProductHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/product/{id}/reviews", InsertReviewHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/categories/{id}", DeleteCategoryHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/feed.xml", ProductFeedHandler(s)).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/cart/items", AddCartItemHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/cart/coupon", ApplyCartCouponHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/coupons/{code}", DeleteCouponHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/orders", PlaceOrderHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/orders/{id}/status", UpdateOrderStatusHandler(s)).Methods(http.MethodPatch)
+	r.HandleFunc("/orders/{id}/pay", PayOrderHandler(s)).Methods(http.MethodPost)
+	return &testServer{server: s, router: r}
+}
+
+// token guarda un usuario con el rol pedido y devuelve su access token.
+func (ts *testServer) token(t *testing.T, role models.Role) string {
+	t.Helper()
+	id, err := ksuid.NewRandom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Id: id.String(), Email: id.String() + "@example.com", Role: role}
+	if err := repository.InsertUser(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	response, err := issueTokens(context.Background(), ts.server, user, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Token
+}
+
+func (ts *testServer) do(method string, path string, token string, body string, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, r)
+	return w
+}
+
+// mustDo es do cuando el test no puede seguir si la respuesta no es want.
+func (ts *testServer) mustDo(t *testing.T, want int, method string, path string, token string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := ts.do(method, path, token, body)
+	if w.Code != want {
+		t.Fatalf("%s %s = %d %s, want %d", method, path, w.Code, strings.TrimSpace(w.Body.String()), want)
+	}
+	return w
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+// insertProduct crea un producto por la API y devuelve su id.
+func (ts *testServer) insertProduct(t *testing.T, token string, body string) string {
+	t.Helper()
+	var product PostResponse
+	decodeBody(t, ts.mustDo(t, http.StatusOK, http.MethodPost, "/product", token, body), &product)
+	return product.Id
+}
+
+const testAddress = `{"name":"Ada","line1":"1 Market St","city":"San Francisco","region":"CA","postal_code":"94105","country":"US"}`
+
+type testMessage struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// listen conecta un cliente websocket al hub y devuelve una función que lee el
+// siguiente mensaje. El hub registra al cliente después del handshake, así que
+// se manda un mensaje de prueba hasta que llega uno; esos no se devuelven.
+func (ts *testServer) listen(t *testing.T) func() testMessage {
+	t.Helper()
+	hub := ts.server.Hub()
+	go hub.Run()
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleWebSocket))
+	t.Cleanup(server.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	messages := make(chan testMessage, 16)
+	go func() {
+		for {
+			var message testMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				close(messages)
+				return
+			}
+			messages <- message
+		}
+	}()
+	for registered := false; !registered; {
+		hub.Broadcast(models.WebsocketMessage{Type: "probe"})
+		select {
+		case <-messages:
+			registered = true
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+
+	return func() testMessage {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					t.Fatal("websocket closed")
+				}
+				if message.Type != "probe" {
+					return message
+				}
+			case <-timeout:
+				t.Fatal("no websocket message")
+			}
+		}
+	}
+}
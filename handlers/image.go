@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"slices"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/cristiangar0398/ShopAPI/storage"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	maxImagesPerUpload = 10
+	maxImagePixels     = 40_000_000
+)
+
+type ReorderImagesRequest struct {
+	ImageIds []string `json:"image_ids"`
+}
+
+// imageUpload es un archivo cuyo tipo y dimensiones ya se revisaron. La imagen
+// se decodifica recién al guardarla, de a una por vez.
+type imageUpload struct {
+	header      *multipart.FileHeader
+	contentType string
+	extension   string
+}
+
+func ListProductImagesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		images, err := repository.ListProductImages(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(images)
+	}
+}
+
+// UploadProductImagesHandler recibe uno o varios archivos en el campo "image"
+// de un formulario multipart. Se validan todos antes de guardar ninguno y, si
+// uno falla al guardarse, se borran los que ya se habían guardado.
+func UploadProductImagesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		if s.Blobs() == nil {
+			http.Error(w, "image storage is not configured", http.StatusServiceUnavailable)
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImagesPerUpload*models.MaxImageSize+1<<20)
+		if err := r.ParseMultipartForm(models.MaxImageSize); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "upload is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		files := r.MultipartForm.File["image"]
+		if len(files) == 0 {
+			http.Error(w, "image file is required", http.StatusBadRequest)
+			return
+		}
+		if len(files) > maxImagesPerUpload {
+			http.Error(w, "too many images in one upload", http.StatusBadRequest)
+			return
+		}
+		uploads := []*imageUpload{}
+		for _, header := range files {
+			upload, status, err := readImageUpload(header)
+			if err != nil {
+				http.Error(w, header.Filename+": "+err.Error(), status)
+				return
+			}
+			uploads = append(uploads, upload)
+		}
+
+		images := []*models.ProductImage{}
+		for _, upload := range uploads {
+			stored, status, err := storeProductImage(r.Context(), s, product.Id, upload)
+			if err != nil {
+				removeProductImages(r.Context(), s, product.Id, images)
+				http.Error(w, upload.header.Filename+": "+err.Error(), status)
+				return
+			}
+			images = append(images, stored)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(images)
+	}
+}
+
+func ReorderProductImagesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = ReorderImagesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		images, err := repository.ListProductImages(r.Context(), product.Id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		current := []string{}
+		for _, stored := range images {
+			current = append(current, stored.Id)
+		}
+		requested := slices.Clone(request.ImageIds)
+		slices.Sort(current)
+		slices.Sort(requested)
+		if !slices.Equal(current, requested) {
+			http.Error(w, "image_ids must list every image of the product once", http.StatusBadRequest)
+			return
+		}
+
+		if err := repository.ReorderProductImages(r.Context(), product.Id, request.ImageIds); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		images, err = repository.ListProductImages(r.Context(), product.Id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(images)
+	}
+}
+
+func DeleteProductImageHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		params := mux.Vars(r)
+		images, err := repository.ListProductImages(r.Context(), product.Id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		index := slices.IndexFunc(images, func(stored *models.ProductImage) bool { return stored.Id == params["imageId"] })
+		if index < 0 {
+			http.Error(w, "image not found", http.StatusNotFound)
+			return
+		}
+
+		if err := repository.DeleteProductImage(r.Context(), product.Id, images[index].Id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		deleteImageBlobs(r.Context(), s, images[index:index+1])
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Delete image",
+		})
+	}
+}
+
+// readImageUpload revisa tamaño, tipo real del contenido y dimensiones leyendo
+// solo la cabecera de la imagen; no la decodifica.
+func readImageUpload(header *multipart.FileHeader) (*imageUpload, int, error) {
+	if header.Size > models.MaxImageSize {
+		return nil, http.StatusRequestEntityTooLarge, errors.New("image is larger than 5MB")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer file.Close()
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, http.StatusBadRequest, err
+	}
+	contentType := http.DetectContentType(sniff[:n])
+	extension, ok := models.ImageExtensions[contentType]
+	if !ok {
+		return nil, http.StatusUnsupportedMediaType, errors.New("only jpeg, png and gif images are allowed")
+	}
+	config, _, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(sniff[:n]), file))
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid image")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, http.StatusBadRequest, errors.New("image dimensions are too large")
+	}
+	return &imageUpload{header: header, contentType: contentType, extension: extension}, 0, nil
+}
+
+// storeProductImage decodifica la imagen, guarda el original y su miniatura en
+// el BlobStore y registra la imagen al final de las del producto. Las
+// miniaturas de png y gif se guardan como png para conservar la transparencia.
+func storeProductImage(ctx context.Context, s server.Server, productID string, upload *imageUpload) (*models.ProductImage, int, error) {
+	file, err := upload.header.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxImageSize+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len(data) > models.MaxImageSize {
+		return nil, http.StatusRequestEntityTooLarge, errors.New("image is larger than 5MB")
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid image")
+	}
+
+	var thumbnail bytes.Buffer
+	thumbnailType := "image/png"
+	resized := storage.Thumbnail(decoded, models.ThumbnailSize)
+	if upload.contentType == "image/jpeg" {
+		thumbnailType = "image/jpeg"
+		err = jpeg.Encode(&thumbnail, resized, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&thumbnail, resized)
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	id, err := ksuid.NewRandom()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	prefix := "products/" + productID + "/" + id.String()
+	stored := &models.ProductImage{
+		Id:           id.String(),
+		ProductId:    productID,
+		ContentType:  upload.contentType,
+		Size:         int64(len(data)),
+		Key:          prefix + upload.extension,
+		ThumbnailKey: prefix + "_thumb" + models.ImageExtensions[thumbnailType],
+	}
+	if stored.Url, err = s.Blobs().Put(ctx, stored.Key, bytes.NewReader(data), upload.contentType); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if stored.ThumbnailUrl, err = s.Blobs().Put(ctx, stored.ThumbnailKey, &thumbnail, thumbnailType); err != nil {
+		deleteImageBlobs(ctx, s, []*models.ProductImage{stored})
+		return nil, http.StatusInternalServerError, err
+	}
+	if err := repository.InsertProductImage(ctx, stored); err != nil {
+		deleteImageBlobs(ctx, s, []*models.ProductImage{stored})
+		return nil, http.StatusInternalServerError, err
+	}
+	return stored, 0, nil
+}
+
+// removeProductImages deshace las imágenes ya guardadas de una subida fallida.
+func removeProductImages(ctx context.Context, s server.Server, productID string, images []*models.ProductImage) {
+	for _, stored := range images {
+		if err := repository.DeleteProductImage(ctx, productID, stored.Id); err != nil {
+			log.Println("images:", err)
+		}
+	}
+	deleteImageBlobs(ctx, s, images)
+}
+
+// deleteImageBlobs borra los archivos de las imágenes; si falla solo queda un
+// archivo huérfano, así que el error se registra y no se devuelve.
+func deleteImageBlobs(ctx context.Context, s server.Server, images []*models.ProductImage) {
+	if s.Blobs() == nil {
+		return
+	}
+	for _, stored := range images {
+		for _, key := range []string{stored.Key, stored.ThumbnailKey} {
+			if err := s.Blobs().Delete(ctx, key); err != nil {
+				log.Println("images:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	maxImportRows  = 5000
+	maxImportBytes = 10 << 20
+)
+
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	Id     string   `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowResult `json:"rows"`
+}
+
+// ImportProductsHandler recibe un CSV con cabecera (title, description,
+// image_url, price, currency) o NDJSON con esos mismos campos. Si alguna fila falla no
+// se guarda nada; con dry_run=true solo se valida.
+func ImportProductsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		format, err := importFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+		if r.URL.Query().Get("dry_run") != "" && err != nil {
+			http.Error(w, "invalid dry_run", http.StatusBadRequest)
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+		var requests []*UpsertPostRequest
+		var rows []*ImportRowResult
+		if format == "csv" {
+			requests, rows, err = readProductsCSV(body)
+		} else {
+			requests, rows, err = readProductsNDJSON(body)
+		}
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "import is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(rows) == 0 {
+			http.Error(w, "import has no rows", http.StatusBadRequest)
+			return
+		}
+
+		report := &ImportReport{DryRun: dryRun, Rows: rows}
+		products := []*models.Products{}
+		created := []*ImportRowResult{}
+		for i, request := range requests {
+			if request == nil {
+				continue
+			}
+			if errs := validateImportedProduct(request); len(errs) > 0 {
+				rows[i].Errors = errs
+				continue
+			}
+			if err := checkProductCurrency(r.Context(), s, request.Currency); err != nil {
+				if currencyErrorStatus(err) != http.StatusBadRequest {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				rows[i].Errors = []string{err.Error()}
+				continue
+			}
+			id, err := ksuid.NewRandom()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			products = append(products, &models.Products{
+				Id:          id.String(),
+				Title:       strings.TrimSpace(request.Title),
+				Description: request.Description,
+				ImageUrl:    request.ImageUrl,
+				Price:       request.Price,
+				Currency:    request.Currency,
+				TaxClass:    models.NormalizeTaxClass(request.TaxClass),
+				Dimensions:  request.Dimensions,
+				UserId:      claims.UserId,
+			})
+			created = append(created, rows[i])
+		}
+		for _, row := range rows {
+			if len(row.Errors) > 0 {
+				report.Failed++
+			}
+		}
+
+		status := http.StatusOK
+		switch {
+		case report.Failed > 0:
+			status = http.StatusUnprocessableEntity
+		case !dryRun:
+			if err := repository.InsertProducts(r.Context(), products); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for i, row := range created {
+				row.Id = products[i].Id
+			}
+			for _, product := range products {
+				s.Hub().Broadcast(models.WebsocketMessage{
+					Type:    ProductCreatedMessage,
+					Payload: product,
+				})
+			}
+			report.Created = len(products)
+			status = http.StatusCreated
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// importFormat toma el formato del parámetro format o, si no viene, del
+// Content-Type.
+func importFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			format = "csv"
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			format = "ndjson"
+		}
+	}
+	if format != "csv" && format != "ndjson" {
+		return "", errors.New("format must be csv or ndjson")
+	}
+	return format, nil
+}
+
+// readProductsCSV devuelve una petición por fila de datos; las filas que no se
+// pudieron leer quedan en nil con su error en el reporte.
+func readProductsCSV(body io.Reader) ([]*UpsertPostRequest, []*ImportRowResult, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("csv header is required")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "title", "description", "image_url", "price", "currency", "tax_class", "weight_grams", "length_mm", "width_mm", "height_mm":
+			columns[name] = i
+		default:
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, errors.New("csv must have a title column")
+	}
+	if _, ok := columns["price"]; !ok {
+		return nil, nil, errors.New("csv must have a price column")
+	}
+
+	requests := []*UpsertPostRequest{}
+	rows := []*ImportRowResult{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, nil, err
+		}
+		if len(rows) == maxImportRows {
+			return nil, nil, fmt.Errorf("import is limited to %d rows", maxImportRows)
+		}
+		row := &ImportRowResult{Row: len(rows) + 1}
+		rows = append(rows, row)
+		if err != nil {
+			row.Errors = []string{err.Error()}
+			requests = append(requests, nil)
+			continue
+		}
+		if len(record) != len(header) {
+			row.Errors = []string{fmt.Sprintf("expected %d fields, got %d", len(header), len(record))}
+			requests = append(requests, nil)
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		request := &UpsertPostRequest{
+			Title:       field("title"),
+			Description: field("description"),
+			ImageUrl:    field("image_url"),
+			Currency:    models.NormalizeCurrency(field("currency")),
+			TaxClass:    field("tax_class"),
+		}
+		price, err := models.ParseMoney(field("price"), request.Currency)
+		if err != nil {
+			row.Errors = []string{"price must be a number"}
+			requests = append(requests, nil)
+			continue
+		}
+		request.Price = price
+		if err := parseDimensions(&request.Dimensions, field); err != nil {
+			row.Errors = []string{err.Error()}
+			requests = append(requests, nil)
+			continue
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows, nil
+}
+
+// parseDimensions lee las columnas de peso y medidas; una celda vacía deja el
+// valor en cero.
+func parseDimensions(dimensions *models.Dimensions, field func(name string) string) error {
+	columns := []struct {
+		name  string
+		value *int
+	}{
+		{"weight_grams", &dimensions.WeightGrams},
+		{"length_mm", &dimensions.LengthMm},
+		{"width_mm", &dimensions.WidthMm},
+		{"height_mm", &dimensions.HeightMm},
+	}
+	for _, column := range columns {
+		value := strings.TrimSpace(field(column.name))
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer", column.name)
+		}
+		*column.value = parsed
+	}
+	return nil
+}
+
+func readProductsNDJSON(body io.Reader) ([]*UpsertPostRequest, []*ImportRowResult, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	requests := []*UpsertPostRequest{}
+	rows := []*ImportRowResult{}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, nil, fmt.Errorf("import is limited to %d rows", maxImportRows)
+		}
+		row := &ImportRowResult{Row: len(rows) + 1}
+		rows = append(rows, row)
+
+		request, err := decodeProductRequest([]byte(line), true)
+		if err != nil {
+			row.Errors = []string{err.Error()}
+			requests = append(requests, nil)
+			continue
+		}
+		requests = append(requests, &request)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return requests, rows, nil
+}
+
+func validateImportedProduct(request *UpsertPostRequest) []string {
+	errs := []string{}
+	title := strings.TrimSpace(request.Title)
+	if title == "" {
+		errs = append(errs, "title is required")
+	}
+	if utf8.RuneCountInString(title) > 225 {
+		errs = append(errs, "title must be at most 225 characters")
+	}
+	if err := validatePrice(request.Price); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if !models.ValidCurrency(request.Currency) {
+		errs = append(errs, "currency must be a three-letter ISO code")
+	}
+	if !models.ValidTaxClass(models.NormalizeTaxClass(request.TaxClass)) {
+		errs = append(errs, "invalid tax_class")
+	}
+	if err := request.Dimensions.Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	return errs
+}
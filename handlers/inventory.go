@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+)
+
+// SetInventoryRequest reemplaza el inventario del producto. Sin stock el
+// producto deja de llevar control de inventario; Variants fija el stock de
+// las variantes indicadas por id.
+type SetInventoryRequest struct {
+	Stock    *int           `json:"stock"`
+	Variants map[string]int `json:"variants"`
+}
+
+func GetInventoryHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		writeInventory(w, r, product.Id)
+	}
+}
+
+func SetInventoryHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = SetInventoryRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		if request.Stock != nil && *request.Stock < 0 {
+			http.Error(w, "stock must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		variants := []*models.ProductVariant{}
+		for variantID, stock := range request.Variants {
+			variant, err := repository.GetVariantById(r.Context(), variantID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if variant == nil || variant.ProductId != product.Id {
+				http.Error(w, "variant "+variantID+" not found", http.StatusBadRequest)
+				return
+			}
+			if stock < 0 {
+				http.Error(w, "stock must not be negative", http.StatusBadRequest)
+				return
+			}
+			variant.Stock = stock
+			variants = append(variants, variant)
+		}
+
+		if err := repository.SetInventory(r.Context(), product.Id, request.Stock); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, variant := range variants {
+			if err := repository.UpdateVariant(r.Context(), variant); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		writeInventory(w, r, product.Id)
+	}
+}
+
+func writeInventory(w http.ResponseWriter, r *http.Request, productID string) {
+	inventory, err := repository.GetInventory(r.Context(), productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if inventory == nil {
+		inventory = &models.Inventory{ProductId: productID}
+	}
+
+	variants, err := repository.ListVariants(r.Context(), productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	inventory.Variants = []*models.VariantStock{}
+	for _, variant := range variants {
+		inventory.Variants = append(inventory.Variants, &models.VariantStock{Id: variant.Id, Sku: variant.Sku, Stock: variant.Stock})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inventory)
+}
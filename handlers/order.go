@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+type UpdateOrderStatusRequest struct {
+	Status models.OrderStatus `json:"status"`
+}
+
+// PlaceOrderRequest es opcional; sin método de envío la orden no lleva envío y
+// con método hace falta una dirección de envío guardada.
+// La región de los impuestos sale siempre de una dirección guardada: la
+// pedida, la de envío por defecto o la de facturación por defecto. Sin ninguna
+// la orden va sin región y sin impuestos, como el carrito sin region.
+type PlaceOrderRequest struct {
+	AddressId        string `json:"address_id"`
+	ShippingMethodId string `json:"shipping_method_id"`
+}
+
+func PlaceOrderHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = PlaceOrderRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		address, taxAddress, err := orderAddresses(r.Context(), claims.UserId, request.AddressId)
+		if errors.Is(err, models.ErrAddressNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		region := ""
+		if taxAddress != nil {
+			region = taxAddress.Destination()
+		}
+		cart, err := repository.GetCart(r.Context(), claims.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := priceCart(r.Context(), s, cart, region); err != nil {
+			http.Error(w, err.Error(), currencyErrorStatus(err))
+			return
+		}
+		if request.ShippingMethodId != "" && !applyShipping(w, r, cart, request.ShippingMethodId, address) {
+			return
+		}
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		order, err := models.NewOrderFromCart(id.String(), cart)
+		if errors.Is(err, models.ErrEmptyCart) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if cart.CouponError != "" {
+			http.Error(w, cart.CouponError, http.StatusConflict)
+			return
+		}
+		order.ShippingAddress = address
+
+		err = repository.InsertOrder(r.Context(), order)
+		if errors.Is(err, models.ErrOutOfStock) || errors.Is(err, models.ErrInvalidCoupon) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(order)
+	}
+}
+
+// orderAddresses devuelve la dirección de envío, la pedida o la de envío por
+// defecto, y la que fija los impuestos: la de envío o, si no hay, la de
+// facturación por defecto. El ?region= del carrito no se usa al comprar.
+func orderAddresses(ctx context.Context, userID string, addressID string) (*models.Address, *models.Address, error) {
+	if addressID != "" {
+		address, err := repository.GetAddressById(ctx, userID, addressID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if address == nil {
+			return nil, nil, models.ErrAddressNotFound
+		}
+		return address, address, nil
+	}
+	shipping, billing, err := defaultAddresses(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shipping != nil {
+		return shipping, shipping, nil
+	}
+	return nil, billing, nil
+}
+
+func ListOrdersHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		orders, err := repository.ListOrders(r.Context(), claims.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(orders)
+	}
+}
+
+func GetOrderByIdHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		order, ok := ownedOrder(w, r, claims)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(order)
+	}
+}
+
+func UpdateOrderStatusHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = UpdateOrderStatusRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !request.Status.Valid() {
+			http.Error(w, "unknown order status", http.StatusBadRequest)
+			return
+		}
+
+		order, ok := orderForStatusChange(w, r, claims)
+		if !ok {
+			return
+		}
+		if !canChangeOrderStatus(claims, request.Status) {
+			http.Error(w, "not allowed to set this status", http.StatusForbidden)
+			return
+		}
+
+		if !order.Status.CanTransitionTo(request.Status) {
+			http.Error(w, models.ErrInvalidOrderTransition.Error(), http.StatusConflict)
+			return
+		}
+		// Una orden pagada se reembolsa antes de cancelarla; si el reembolso
+		// falla la orden sigue pagada y se puede reintentar. Los pagos ya
+		// reembolsados no se vuelven a tocar.
+		if order.Status == models.OrderPaid && request.Status == models.OrderCancelled {
+			if err := refundOrder(r.Context(), s.Payments(), order); err != nil {
+				http.Error(w, "refund failed, the order was not cancelled: "+err.Error(), http.StatusBadGateway)
+				return
+			}
+		}
+		order, err := transitionOrder(r.Context(), order, request.Status)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidOrderTransition) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(order)
+	}
+}
+
+// ownedOrder busca la orden de la ruta y responde 404 si no existe o si no es
+// del usuario del token, para no revelar órdenes ajenas.
+func ownedOrder(w http.ResponseWriter, r *http.Request, claims *models.AppClaims) (*models.Order, bool) {
+	params := mux.Vars(r)
+	order, err := repository.GetOrderById(r.Context(), params["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if order == nil || order.UserId != claims.UserId {
+		http.Error(w, "order not found", http.StatusNotFound)
+		return nil, false
+	}
+	return order, true
+}
+
+// orderForStatusChange permite a los admins operar sobre cualquier orden; el
+// resto solo sobre las suyas.
+func orderForStatusChange(w http.ResponseWriter, r *http.Request, claims *models.AppClaims) (*models.Order, bool) {
+	if !claims.HasRole(models.RoleAdmin) {
+		return ownedOrder(w, r, claims)
+	}
+	params := mux.Vars(r)
+	order, err := repository.GetOrderById(r.Context(), params["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if order == nil {
+		http.Error(w, "order not found", http.StatusNotFound)
+		return nil, false
+	}
+	return order, true
+}
+
+// Los clientes solo pueden cancelar sus órdenes; envío y entrega los marca un
+// admin. El pago lo hace PayOrderHandler.
+func canChangeOrderStatus(claims *models.AppClaims, next models.OrderStatus) bool {
+	switch next {
+	case models.OrderCancelled:
+		return true
+	case models.OrderShipped, models.OrderDelivered:
+		return claims.HasRole(models.RoleAdmin)
+	}
+	return false
+}
+
+// transitionOrder valida el cambio contra la máquina de estados y devuelve la
+// orden actualizada.
+func transitionOrder(ctx context.Context, order *models.Order, next models.OrderStatus) (*models.Order, error) {
+	if !order.Status.CanTransitionTo(next) {
+		return nil, models.ErrInvalidOrderTransition
+	}
+	if err := repository.UpdateOrderStatus(ctx, order.Id, order.Status, next); err != nil {
+		return nil, err
+	}
+	return repository.GetOrderById(ctx, order.Id)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/payment"
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+func TestPlaceOrderHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		address   bool
+		quantity  int
+		stock     int
+		body      string
+		want      int
+		wantTotal string
+	}{
+		{name: "places the order", address: true, quantity: 2, want: http.StatusCreated, wantTotal: "20.00"},
+		{name: "reserves tracked stock", address: true, quantity: 2, stock: 2, want: http.StatusCreated, wantTotal: "20.00"},
+		{name: "out of stock", address: true, quantity: 3, stock: 2, want: http.StatusConflict},
+		{name: "without an address", quantity: 1, want: http.StatusCreated, wantTotal: "10.00"},
+		{name: "unknown address", address: true, quantity: 1, body: `{"address_id":"missing"}`, want: http.StatusNotFound},
+		{name: "empty cart", address: true, want: http.StatusBadRequest},
+		{name: "malformed json", address: true, quantity: 1, body: `{"address_id":`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServer(t)
+			seller := ts.token(t, models.RoleSeller)
+			customer := ts.token(t, models.RoleCustomer)
+			productID := ts.insertProduct(t, seller, `{"title":"Lamp","price":10}`)
+			if tt.stock > 0 {
+				if err := repository.SetInventory(ctx, productID, &tt.stock); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.address {
+				ts.mustDo(t, http.StatusCreated, http.MethodPost, "/me/addresses", customer, testAddress)
+			}
+			if tt.quantity > 0 {
+				body := `{"product_id":"` + productID + `","quantity":` + strconv.Itoa(tt.quantity) + `}`
+				ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/items", customer, body)
+			}
+
+			w := ts.do(http.MethodPost, "/orders", customer, tt.body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			inventory, _ := repository.GetInventory(ctx, productID)
+			if tt.want != http.StatusCreated {
+				if inventory != nil && inventory.Stock != tt.stock {
+					t.Errorf("stock after failed order = %d, want %d", inventory.Stock, tt.stock)
+				}
+				return
+			}
+			var order models.Order
+			decodeBody(t, w, &order)
+			wantRegion := ""
+			if tt.address {
+				wantRegion = "US-CA"
+			}
+			if order.Status != models.OrderPending || order.Total.String() != tt.wantTotal || order.TaxRegion != wantRegion {
+				t.Errorf("order = %s total %s region %q, want pending total %s region %q", order.Status, order.Total, order.TaxRegion, tt.wantTotal, wantRegion)
+			}
+			if tt.address && (order.ShippingAddress == nil || order.ShippingAddress.PostalCode != "94105") {
+				t.Errorf("shipping address = %+v", order.ShippingAddress)
+			}
+			if inventory != nil && (inventory.Stock != tt.stock-tt.quantity || inventory.Reserved != tt.quantity) {
+				t.Errorf("inventory = stock %d reserved %d", inventory.Stock, inventory.Reserved)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderStatusHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		paid       bool
+		role       models.Role
+		otherUser  bool
+		status     string
+		want       int
+		wantStatus models.OrderStatus
+	}{
+		{name: "customer cancels", role: models.RoleCustomer, status: "cancelled", want: http.StatusOK, wantStatus: models.OrderCancelled},
+		{name: "customer cannot ship", role: models.RoleCustomer, status: "shipped", want: http.StatusForbidden},
+		{name: "customer cannot pay", role: models.RoleCustomer, status: "paid", want: http.StatusForbidden},
+		{name: "other customer sees nothing", role: models.RoleCustomer, otherUser: true, status: "cancelled", want: http.StatusNotFound},
+		{name: "admin ships paid order", paid: true, role: models.RoleAdmin, status: "shipped", want: http.StatusOK, wantStatus: models.OrderShipped},
+		{name: "admin cannot ship unpaid order", role: models.RoleAdmin, status: "shipped", want: http.StatusConflict},
+		{name: "admin cannot deliver unshipped order", paid: true, role: models.RoleAdmin, status: "delivered", want: http.StatusConflict},
+		{name: "admin cancels any order", role: models.RoleAdmin, otherUser: true, status: "cancelled", want: http.StatusOK, wantStatus: models.OrderCancelled},
+		{name: "unknown status", role: models.RoleAdmin, status: "lost", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServer(t)
+			customer := ts.token(t, models.RoleCustomer)
+			order := ts.placeOrder(t, customer)
+			if tt.paid {
+				if err := repository.UpdateOrderStatus(ctx, order.Id, models.OrderPending, models.OrderPaid); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			token := customer
+			if tt.role != models.RoleCustomer || tt.otherUser {
+				token = ts.token(t, tt.role)
+			}
+			w := ts.do(http.MethodPatch, "/orders/"+order.Id+"/status", token, `{"status":"`+tt.status+`"}`)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			stored, _ := repository.GetOrderById(ctx, order.Id)
+			wantStatus := tt.wantStatus
+			if w.Code != http.StatusOK {
+				wantStatus = models.OrderPending
+				if tt.paid {
+					wantStatus = models.OrderPaid
+				}
+			}
+			if stored.Status != wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, wantStatus)
+			}
+		})
+	}
+}
+
+func TestCancelPaidOrderRefundsFirst(t *testing.T) {
+	tests := []struct {
+		name        string
+		breakRefund bool
+		want        int
+		wantStatus  models.OrderStatus
+		wantPayment payment.Status
+	}{
+		{name: "refund succeeds", want: http.StatusOK, wantStatus: models.OrderCancelled, wantPayment: payment.StatusRefunded},
+		{name: "refund fails", breakRefund: true, want: http.StatusBadGateway, wantStatus: models.OrderPaid, wantPayment: payment.StatusCaptured},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServer(t)
+			customer := ts.token(t, models.RoleCustomer)
+			order := ts.placeOrder(t, customer)
+			ts.mustDo(t, http.StatusOK, http.MethodPost, "/orders/"+order.Id+"/pay", customer, `{"source":"tok_visa"}`)
+			payments, _ := repository.ListPayments(ctx, order.Id)
+			if tt.breakRefund {
+				// La pasarela no conoce la referencia y rechaza el reembolso.
+				payments[0].Reference = "unknown"
+				if err := repository.UpdatePayment(ctx, payments[0]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := ts.do(http.MethodPatch, "/orders/"+order.Id+"/status", customer, `{"status":"cancelled"}`)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			stored, _ := repository.GetOrderById(ctx, order.Id)
+			payments, _ = repository.ListPayments(ctx, order.Id)
+			if stored.Status != tt.wantStatus || payments[0].Status != string(tt.wantPayment) {
+				t.Errorf("order %s with payment %s, want %s with %s", stored.Status, payments[0].Status, tt.wantStatus, tt.wantPayment)
+			}
+		})
+	}
+}
+
+// placeOrder compra una unidad de un producto nuevo con una dirección guardada.
+func (ts *testServer) placeOrder(t *testing.T, customer string) models.Order {
+	t.Helper()
+	productID := ts.insertProduct(t, ts.token(t, models.RoleSeller), `{"title":"Lamp","price":10}`)
+	ts.mustDo(t, http.StatusCreated, http.MethodPost, "/me/addresses", customer, testAddress)
+	ts.mustDo(t, http.StatusOK, http.MethodPost, "/cart/items", customer, `{"product_id":"`+productID+`"}`)
+	var order models.Order
+	decodeBody(t, ts.mustDo(t, http.StatusCreated, http.MethodPost, "/orders", customer, ""), &order)
+	return order
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/payment"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/segmentio/ksuid"
+)
+
+const paymentTimeout = 15 * time.Second
+
+type PayOrderRequest struct {
+	Source string `json:"source"`
+}
+
+type PayOrderResponse struct {
+	Order   *models.Order   `json:"order"`
+	Payment *models.Payment `json:"payment"`
+}
+
+func PayOrderHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = PayOrderRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		order, ok := ownedOrder(w, r, claims)
+		if !ok {
+			return
+		}
+		if order.Status != models.OrderPending {
+			http.Error(w, "order is not pending payment", http.StatusConflict)
+			return
+		}
+		// Un cupón puede dejar la orden en cero: no hay nada que cobrar y la
+		// pasarela rechaza montos nulos, así que se marca pagada sin pago.
+		if order.Total.Amount <= 0 {
+			paid, err := transitionOrder(r.Context(), order, models.OrderPaid)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(PayOrderResponse{Order: paid})
+			return
+		}
+
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		attempt := &models.Payment{
+			Id:       id.String(),
+			OrderId:  order.Id,
+			UserId:   claims.UserId,
+			Provider: s.Payments().Name(),
+			Amount:   order.Total,
+			Currency: payment.DefaultCurrency,
+			Status:   string(payment.StatusPending),
+		}
+		if err := repository.InsertPayment(r.Context(), attempt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := chargeOrder(r.Context(), s.Payments(), attempt, request.Source); err != nil {
+			http.Error(w, err.Error(), paymentErrorStatus(err))
+			return
+		}
+
+		paid, err := transitionOrder(r.Context(), order, models.OrderPaid)
+		if err != nil {
+			// La orden cambió mientras se cobraba; se devuelve el dinero.
+			refundPayment(r.Context(), s.Payments(), attempt)
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PayOrderResponse{
+			Order:   paid,
+			Payment: attempt,
+		})
+	}
+}
+
+func ListOrderPaymentsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		order, ok := ownedOrder(w, r, claims)
+		if !ok {
+			return
+		}
+		payments, err := repository.ListPayments(r.Context(), order.Id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(payments)
+	}
+}
+
+func PaymentWebhookHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		event, err := s.Payments().VerifyWebhook(r)
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		stored, err := repository.GetPaymentByReference(r.Context(), event.Reference)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if stored == nil {
+			http.Error(w, payment.ErrUnknownReference.Error(), http.StatusNotFound)
+			return
+		}
+		// Un webhook repetido o uno que llega después del reembolso no cambia nada.
+		if stored.Status == string(event.Status) || stored.Status == string(payment.StatusRefunded) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(PostUpdateResponse{
+				Message: "Payment " + stored.Status,
+			})
+			return
+		}
+		stored.Status = string(event.Status)
+		if err := repository.UpdatePayment(r.Context(), stored); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := settleOrderPayment(r.Context(), s.Payments(), stored); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Payment " + stored.Status,
+		})
+	}
+}
+
+// settleOrderPayment lleva la orden al estado que indica el webhook, igual que
+// PayOrderHandler: un cobro capturado la marca pagada y confirma las reservas;
+// uno rechazado o fallido la cancela y libera el stock si no queda otro intento
+// abierto. Si la orden ya no admite el pago se devuelve el dinero.
+func settleOrderPayment(ctx context.Context, provider payment.Provider, attempt *models.Payment) error {
+	order, err := repository.GetOrderById(ctx, attempt.OrderId)
+	if err != nil || order == nil {
+		return err
+	}
+	switch payment.Status(attempt.Status) {
+	case payment.StatusCaptured:
+		if order.Status != models.OrderPending {
+			if order.Status == models.OrderCancelled {
+				return refundPayment(ctx, provider, attempt)
+			}
+			return nil
+		}
+		_, err := transitionOrder(ctx, order, models.OrderPaid)
+		if errors.Is(err, models.ErrInvalidOrderTransition) {
+			return refundPayment(ctx, provider, attempt)
+		}
+		return err
+	case payment.StatusDeclined, payment.StatusFailed:
+		if order.Status != models.OrderPending {
+			return nil
+		}
+		open, err := hasOpenPayment(ctx, order.Id, attempt.Id)
+		if err != nil || open {
+			return err
+		}
+		_, err = transitionOrder(ctx, order, models.OrderCancelled)
+		if errors.Is(err, models.ErrInvalidOrderTransition) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// hasOpenPayment dice si la orden tiene otro intento de pago en curso o cobrado.
+func hasOpenPayment(ctx context.Context, orderID string, exceptID string) (bool, error) {
+	payments, err := repository.ListPayments(ctx, orderID)
+	if err != nil {
+		return false, err
+	}
+	for _, attempt := range payments {
+		if attempt.Id == exceptID {
+			continue
+		}
+		switch payment.Status(attempt.Status) {
+		case payment.StatusPending, payment.StatusAuthorized, payment.StatusCaptured:
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// chargeOrder autoriza y captura el pago, guardando cada resultado en el
+// intento para poder conciliarlo después con la pasarela.
+func chargeOrder(ctx context.Context, provider payment.Provider, attempt *models.Payment, source string) error {
+	ctx, cancel := context.WithTimeout(ctx, paymentTimeout)
+	defer cancel()
+
+	result, err := provider.Authorize(ctx, payment.AuthorizeRequest{
+		OrderId:  attempt.OrderId,
+		Amount:   attempt.Amount,
+		Currency: attempt.Currency,
+		Source:   source,
+	})
+	if err == nil {
+		recordPaymentResult(attempt, result, nil)
+		result, err = provider.Capture(ctx, result.Reference, attempt.Amount)
+	}
+	recordPaymentResult(attempt, result, err)
+
+	// El registro se guarda aunque el contexto de la petición haya vencido.
+	if uerr := repository.UpdatePayment(context.WithoutCancel(ctx), attempt); uerr != nil {
+		log.Println(uerr)
+	}
+	return err
+}
+
+func refundPayment(ctx context.Context, provider payment.Provider, attempt *models.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, paymentTimeout)
+	defer cancel()
+
+	result, err := provider.Refund(ctx, attempt.Reference, attempt.Amount)
+	if err != nil {
+		log.Printf("refund of payment %s failed: %v", attempt.Id, err)
+		return err
+	}
+	recordPaymentResult(attempt, result, nil)
+	return repository.UpdatePayment(context.WithoutCancel(ctx), attempt)
+}
+
+// refundOrder devuelve los pagos capturados de una orden que se va a cancelar.
+func refundOrder(ctx context.Context, provider payment.Provider, order *models.Order) error {
+	payments, err := repository.ListPayments(ctx, order.Id)
+	if err != nil {
+		return err
+	}
+	for _, attempt := range payments {
+		if attempt.Status != string(payment.StatusCaptured) {
+			continue
+		}
+		if err := refundPayment(ctx, provider, attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recordPaymentResult(attempt *models.Payment, result *payment.Result, err error) {
+	if result != nil {
+		if result.Reference != "" {
+			attempt.Reference = result.Reference
+		}
+		attempt.Status = string(result.Status)
+		if result.Message != "" {
+			attempt.Error = result.Message
+		}
+	}
+	if err != nil {
+		if result == nil || !errors.Is(err, payment.ErrDeclined) {
+			attempt.Status = string(payment.StatusFailed)
+		}
+		attempt.Error = err.Error()
+	}
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, payment.ErrDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
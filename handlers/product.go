@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cristiangar0398/ShopAPI/middleware"
 	"github.com/cristiangar0398/ShopAPI/models"
@@ -14,30 +19,96 @@ import (
 	"github.com/segmentio/ksuid"
 )
 
+// UpsertPostRequest lleva el precio en la moneda del producto; sin currency es
+// DefaultCurrency.
 type UpsertPostRequest struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	ImageUrl    string  `json"image_url"`
-	Price       float64 `json:"price"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	ImageUrl    string       `json:"image_url"`
+	Price       models.Money `json:"price"`
+	Currency    string       `json:"currency,omitempty"`
+	TaxClass    string       `json:"tax_class,omitempty"`
+	models.Dimensions
 }
 
 type PostResponse struct {
-	Id          string  `json:"id`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	ImageUrl    string  `json"image_url"`
-	Price       float64 `json:"price"`
+	Id          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	ImageUrl    string       `json:"image_url"`
+	Price       models.Money `json:"price"`
+	Currency    string       `json:"currency"`
+	TaxClass    string       `json:"tax_class,omitempty"`
+	models.Dimensions
+}
+
+// upsertPostFields tiene los campos de UpsertPostRequest sin su UnmarshalJSON.
+type upsertPostFields UpsertPostRequest
+
+func (request *UpsertPostRequest) UnmarshalJSON(data []byte) error {
+	decoded, err := decodeProductRequest(data, false)
+	if err != nil {
+		return err
+	}
+	*request = decoded
+	return nil
+}
+
+// decodeProductRequest lee la moneda antes que el precio para interpretarlo
+// con los decimales de esa moneda. strict rechaza campos desconocidos.
+func decodeProductRequest(data []byte, strict bool) (UpsertPostRequest, error) {
+	var header struct {
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return UpsertPostRequest{}, err
+	}
+	fields := upsertPostFields{Price: models.NewMoney(0, header.Currency)}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&fields); err != nil {
+		return UpsertPostRequest{}, err
+	}
+	fields.Currency = models.NormalizeCurrency(fields.Currency)
+	return UpsertPostRequest(fields), nil
+}
+
+// maxProductPrice es el primer valor que no entra en NUMERIC(11, 3), en
+// cualquier moneda.
+var maxProductPrice = big.NewRat(100_000_000, 1)
+
+// validatePrice es la misma regla para la API, las variantes y la importación.
+func validatePrice(price models.Money) error {
+	if price.Amount < 0 {
+		return errors.New("price must be a non-negative number")
+	}
+	if price.Rat().Cmp(maxProductPrice) >= 0 {
+		return errors.New("price is too large")
+	}
+	return nil
 }
 
 type PostUpdateResponse struct {
 	Message string `json:"message"`
 }
 
+const (
+	ProductCreatedMessage = "product_created"
+	ProductUpdatedMessage = "product_updated"
+	ProductDeletedMessage = "product_deleted"
+)
+
+type ProductDeletedPayload struct {
+	Id string `json:"id"`
+}
+
 func InsertProducttHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token, err := middleware.TokenParseString(w, s, r)
 		if err != nil {
-			log.Fatal(err)
+			return
 		}
 		if claims, ok := token.Claims.(*models.AppClaims); ok && token.Valid {
 			var productRequest = UpsertPostRequest{}
@@ -45,6 +116,22 @@ func InsertProducttHandler(s server.Server) http.HandlerFunc {
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
 			}
+			if err := validatePrice(productRequest.Price); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if !models.ValidTaxClass(models.NormalizeTaxClass(productRequest.TaxClass)) {
+				http.Error(w, "invalid tax_class", http.StatusBadRequest)
+				return
+			}
+			if err := productRequest.Dimensions.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := checkProductCurrency(r.Context(), s, productRequest.Currency); err != nil {
+				http.Error(w, err.Error(), currencyErrorStatus(err))
+				return
+			}
 			id, err := ksuid.NewRandom()
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -56,6 +143,9 @@ func InsertProducttHandler(s server.Server) http.HandlerFunc {
 				Description: productRequest.Description,
 				ImageUrl:    productRequest.ImageUrl,
 				Price:       productRequest.Price,
+				Currency:    productRequest.Currency,
+				TaxClass:    models.NormalizeTaxClass(productRequest.TaxClass),
+				Dimensions:  productRequest.Dimensions,
 				UserId:      claims.UserId,
 			}
 
@@ -65,6 +155,11 @@ func InsertProducttHandler(s server.Server) http.HandlerFunc {
 				return
 			}
 
+			s.Hub().Broadcast(models.WebsocketMessage{
+				Type:    ProductCreatedMessage,
+				Payload: Product,
+			})
+
 			w.Header().Set("content-type", "aaplication/json")
 			json.NewEncoder(w).Encode(PostResponse{
 				Id:          Product.Id,
@@ -72,6 +167,9 @@ func InsertProducttHandler(s server.Server) http.HandlerFunc {
 				Description: productRequest.Description,
 				ImageUrl:    productRequest.ImageUrl,
 				Price:       productRequest.Price,
+				Currency:    Product.Currency,
+				TaxClass:    Product.TaxClass,
+				Dimensions:  Product.Dimensions,
 			})
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -82,14 +180,42 @@ func InsertProducttHandler(s server.Server) http.HandlerFunc {
 
 func GetProductByIdHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		target, err := requestCurrency(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		params := mux.Vars(r)
 		product, err := repository.GetProductById(r.Context(), params["id"])
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		w.Header().Set("content-type", "aaplication/json")
-		json.NewEncoder(w).Encode(product)
+		if product == nil {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		detail, err := productDetail(r, product)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := localizeProduct(r.Context(), s, &detail.Products, target); err != nil {
+			http.Error(w, err.Error(), currencyErrorStatus(err))
+			return
+		}
+		for _, variant := range detail.Variants {
+			if variant.Price == nil {
+				continue
+			}
+			if err := localizePrice(r.Context(), s, variant.Price, target); err != nil {
+				http.Error(w, err.Error(), currencyErrorStatus(err))
+				return
+			}
+		}
+		w.Header().Add("Vary", acceptCurrencyHeader)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(detail)
 	}
 }
 
@@ -97,7 +223,7 @@ func UpdateProducttHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token, err := middleware.TokenParseString(w, s, r)
 		if err != nil {
-			log.Fatal(err)
+			return
 		}
 		if claims, ok := token.Claims.(*models.AppClaims); ok && token.Valid {
 			var productRequest = UpsertPostRequest{}
@@ -105,21 +231,61 @@ func UpdateProducttHandler(s server.Server) http.HandlerFunc {
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
 			}
-			params := mux.Vars(r)
+			if err := validatePrice(productRequest.Price); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if !models.ValidTaxClass(models.NormalizeTaxClass(productRequest.TaxClass)) {
+				http.Error(w, "invalid tax_class", http.StatusBadRequest)
+				return
+			}
+			if err := productRequest.Dimensions.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := checkProductCurrency(r.Context(), s, productRequest.Currency); err != nil {
+				http.Error(w, err.Error(), currencyErrorStatus(err))
+				return
+			}
+			product, ok := editableProduct(w, r, claims)
+			if !ok {
+				return
+			}
 			NewProduct := models.Products{
-				Id:          params["id"],
+				Id:          product.Id,
 				Title:       productRequest.Title,
 				Description: productRequest.Description,
 				ImageUrl:    productRequest.ImageUrl,
 				Price:       productRequest.Price,
-				UserId:      claims.UserId,
+				Currency:    productRequest.Currency,
+				TaxClass:    models.NormalizeTaxClass(productRequest.TaxClass),
+				Dimensions:  productRequest.Dimensions,
+				UserId:      product.UserId,
 			}
 
 			err = repository.UpdateProduct(r.Context(), &NewProduct)
+			if errors.Is(err, models.ErrProductNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			updated, err := repository.GetProductById(r.Context(), product.Id)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+			if updated == nil {
+				http.Error(w, models.ErrProductNotFound.Error(), http.StatusNotFound)
+				return
+			}
+
+			s.Hub().Broadcast(models.WebsocketMessage{
+				Type:    ProductUpdatedMessage,
+				Payload: updated,
+			})
 			w.Header().Set("content-type", "aaplication/json")
 			json.NewEncoder(w).Encode(PostUpdateResponse{
 				Message: "Product Update",
@@ -133,39 +299,190 @@ func UpdateProducttHandler(s server.Server) http.HandlerFunc {
 
 func ListProductHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var err error
-		pageStr := r.URL.Query().Get("page")
-		var page = uint64(0)
-		if pageStr != "" {
-			page, err = strconv.ParseUint(pageStr, 10, 64)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+		filter, err := productFilterParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		target, err := requestCurrency(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		page, err := repository.ListProducts(r.Context(), filter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, product := range page.Items {
+			if err := localizeProduct(r.Context(), s, product, target); err != nil {
+				http.Error(w, err.Error(), currencyErrorStatus(err))
 				return
 			}
 		}
-		products, err := repository.ListProducts(r.Context(), page)
+		w.Header().Add("Vary", acceptCurrencyHeader)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+func SearchProductHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search, err := productSearchParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		page, err := repository.SearchProducts(r.Context(), search)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(products)
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+func pageParam(r *http.Request) (uint64, error) {
+	pageStr := r.URL.Query().Get("page")
+	if pageStr == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(pageStr, 10, 64)
+}
+
+// productSearchParams lee q, limit y cursor del query string.
+func productSearchParams(r *http.Request) (models.ProductSearch, error) {
+	query := r.URL.Query()
+	search := models.ProductSearch{Query: strings.TrimSpace(query.Get("q"))}
+
+	var err error
+	if search.Limit, err = limitParam(query.Get("limit")); err != nil {
+		return search, err
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		if search.After, err = models.DecodeSearchCursor(cursor); err != nil {
+			return search, err
+		}
+	}
+	return search, search.Validate()
+}
+
+func limitParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	return limit, nil
+}
+
+// productFilterParams lee cursor, limit, min_price, max_price, user_id,
+// category, created_after, created_before, sort y order del query string. Los
+// límites y el orden por precio van en la moneda pedida (currency o
+// Accept-Currency), por defecto la base.
+func productFilterParams(r *http.Request) (models.ProductFilter, error) {
+	query := r.URL.Query()
+	filter := models.ProductFilter{
+		UserId:     query.Get("user_id"),
+		CategoryId: query.Get("category"),
+		SortBy:     query.Get("sort"),
 	}
+
+	var err error
+	if filter.Limit, err = limitParam(query.Get("limit")); err != nil {
+		return filter, err
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		if filter.After, err = models.DecodeProductCursor(cursor); err != nil {
+			return filter, err
+		}
+	}
+	if filter.MinPrice, err = priceParam(query.Get("min_price")); err != nil {
+		return filter, fmt.Errorf("min_price: %w", err)
+	}
+	if filter.MaxPrice, err = priceParam(query.Get("max_price")); err != nil {
+		return filter, fmt.Errorf("max_price: %w", err)
+	}
+	if filter.Currency, err = requestCurrency(r); err != nil {
+		return filter, err
+	}
+	if filter.CreatedAfter, err = timeParam(query.Get("created_after")); err != nil {
+		return filter, fmt.Errorf("created_after: %w", err)
+	}
+	if filter.CreatedBefore, err = timeParam(query.Get("created_before")); err != nil {
+		return filter, fmt.Errorf("created_before: %w", err)
+	}
+
+	switch strings.ToLower(query.Get("order")) {
+	case "", "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, errors.New("order must be asc or desc")
+	}
+	return filter, filter.Validate()
+}
+
+// priceParam lee un límite de precio; su moneda es la del filtro.
+func priceParam(value string) (*big.Rat, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || strings.ContainsAny(value, "/eE") {
+		return nil, fmt.Errorf("%w: %q", models.ErrInvalidMoney, value)
+	}
+	if price.Sign() < 0 {
+		return nil, errors.New("must be a non-negative number")
+	}
+	return price, nil
+}
+
+// timeParam acepta RFC 3339 o solo la fecha (2006-01-02).
+func timeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
 }
 
 func DeleteProductHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token, err := middleware.TokenParseString(w, s, r)
 		if err != nil {
-			log.Fatal(err)
+			return
 		}
 		if claims, ok := token.Claims.(*models.AppClaims); ok && token.Valid {
-			params := mux.Vars(r)
-			err = repository.DeleteProduct(r.Context(), params["id"], claims.UserId)
+			product, ok := editableProduct(w, r, claims)
+			if !ok {
+				return
+			}
+			images, err := repository.ListProductImages(r.Context(), product.Id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			err = repository.DeleteProduct(r.Context(), product.Id, product.UserId)
+			if errors.Is(err, models.ErrProductNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+			deleteImageBlobs(r.Context(), s, images)
+
+			s.Hub().Broadcast(models.WebsocketMessage{
+				Type:    ProductDeletedMessage,
+				Payload: ProductDeletedPayload{Id: product.Id},
+			})
 			w.Header().Set("content-type", "aaplication/json")
 			json.NewEncoder(w).Encode(PostUpdateResponse{
 				Message: "Delete product",
@@ -176,3 +493,23 @@ func DeleteProductHandler(s server.Server) http.HandlerFunc {
 		}
 	}
 }
+
+// editableProduct carga el producto de la ruta si quien llama es su dueño o
+// un admin.
+func editableProduct(w http.ResponseWriter, r *http.Request, claims *models.AppClaims) (*models.Products, bool) {
+	params := mux.Vars(r)
+	product, err := repository.GetProductById(r.Context(), params["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if product == nil {
+		http.Error(w, "product not found", http.StatusNotFound)
+		return nil, false
+	}
+	if product.UserId != claims.UserId && !claims.HasRole(models.RoleAdmin) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return nil, false
+	}
+	return product, true
+}
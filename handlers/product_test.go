@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cristiangar0398/ShopAPI/currency"
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+func TestInsertProductHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		noToken   bool
+		want      int
+		wantPrice models.Money
+	}{
+		{name: "default currency", body: `{"title":"Lamp","price":19.99}`, want: http.StatusOK, wantPrice: models.NewMoney(1999, "USD")},
+		{name: "string price", body: `{"title":"Lamp","price":"5.10"}`, want: http.StatusOK, wantPrice: models.NewMoney(510, "USD")},
+		{name: "price read with currency decimals", body: `{"title":"Lamp","price":"1.234","currency":"kwd"}`, want: http.StatusOK, wantPrice: models.NewMoney(1234, "KWD")},
+		{name: "currency after price", body: `{"title":"Lamp","price":1500,"currency":"JPY"}`, want: http.StatusOK, wantPrice: models.NewMoney(1500, "JPY")},
+		{name: "currency without rate", body: `{"title":"Lamp","price":10,"currency":"EUR"}`, want: http.StatusBadRequest},
+		{name: "invalid currency", body: `{"title":"Lamp","price":10,"currency":"euro"}`, want: http.StatusBadRequest},
+		{name: "invalid price", body: `{"title":"Lamp","price":"cheap"}`, want: http.StatusBadRequest},
+		{name: "negative price", body: `{"title":"Lamp","price":-1}`, want: http.StatusBadRequest},
+		{name: "largest price", body: `{"title":"Lamp","price":"99999999.99"}`, want: http.StatusOK, wantPrice: models.NewMoney(9999999999, "USD")},
+		{name: "price too large", body: `{"title":"Lamp","price":100000000}`, want: http.StatusBadRequest},
+		{name: "price too large in JPY", body: `{"title":"Lamp","price":100000000,"currency":"JPY"}`, want: http.StatusBadRequest},
+		{name: "invalid tax class", body: `{"title":"Lamp","price":10,"tax_class":"Not Valid!"}`, want: http.StatusBadRequest},
+		{name: "negative weight", body: `{"title":"Lamp","price":10,"weight_grams":-1}`, want: http.StatusBadRequest},
+		{name: "malformed json", body: `{"title":`, want: http.StatusBadRequest},
+		{name: "without token", body: `{"title":"Lamp","price":10}`, noToken: true, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			err := ts.server.Rates().(currency.Updater).SetRates(context.Background(), &currency.Rates{
+				Base:  "USD",
+				Rates: map[string]string{"KWD": "0.307", "JPY": "150"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			token := ts.token(t, models.RoleSeller)
+			if tt.noToken {
+				token = ""
+			}
+
+			w := ts.do(http.MethodPost, "/product", token, tt.body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var response PostResponse
+			decodeBody(t, w, &response)
+			product, err := repository.GetProductById(context.Background(), response.Id)
+			if err != nil || product == nil {
+				t.Fatalf("stored product = %v, %v", product, err)
+			}
+			if product.Price != tt.wantPrice || product.Currency != tt.wantPrice.Currency {
+				t.Errorf("stored price = %+v in %s, want %+v", product.Price, product.Currency, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestListProductHandler(t *testing.T) {
+	ts := newTestServer(t)
+	err := ts.server.Rates().(currency.Updater).SetRates(context.Background(), &currency.Rates{
+		Base:  "USD",
+		Rates: map[string]string{"JPY": "150"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := ts.token(t, models.RoleSeller)
+	for _, body := range []string{
+		`{"title":"A","price":30}`,
+		`{"title":"B","price":10}`,
+		`{"title":"C","price":20}`,
+		`{"title":"D","price":15}`,
+		`{"title":"E","price":12,"currency":"JPY"}`,
+	} {
+		ts.insertProduct(t, token, body)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  [][]string
+	}{
+		{"by price in pages", "sort=price&limit=3", [][]string{{"B", "D", "C"}, {"A"}}},
+		{"by price descending", "sort=price&order=desc&limit=2", [][]string{{"A", "C"}, {"D", "B"}}},
+		{"by title", "sort=title&limit=10", [][]string{{"A", "B", "C", "D", "E"}}},
+		{"price range", "sort=title&min_price=10.5&max_price=30", [][]string{{"A", "C", "D"}}},
+		{"by price in yen", "sort=price&currency=JPY", [][]string{{"E"}}},
+		{"yen price range", "min_price=10&currency=JPY", [][]string{{"E"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			for i, want := range tt.want {
+				var page models.ProductPage
+				decodeBody(t, ts.mustDo(t, http.StatusOK, http.MethodGet, "/product?"+query.Encode(), "", ""), &page)
+
+				titles := []string{}
+				for _, product := range page.Items {
+					titles = append(titles, product.Title)
+				}
+				if strings.Join(titles, ",") != strings.Join(want, ",") {
+					t.Fatalf("page %d = %v, want %v", i+1, titles, want)
+				}
+				if last := i == len(tt.want)-1; page.HasMore == last || (page.NextCursor == "") != last {
+					t.Fatalf("page %d has_more %v cursor %q", i+1, page.HasMore, page.NextCursor)
+				}
+				query.Set("cursor", page.NextCursor)
+			}
+		})
+	}
+	for _, query := range []string{"cursor=nope", "limit=0", "min_price=-1", "min_price=1e3", "order=up", "currency=euro"} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			ts.mustDo(t, http.StatusBadRequest, http.MethodGet, "/product?"+query, "", "")
+		})
+	}
+}
+
+func TestUpdateProductHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		caller  string
+		body    string
+		missing bool
+		want    int
+	}{
+		{name: "owner", caller: "owner", want: http.StatusOK},
+		{name: "negative price", caller: "owner", body: `{"title":"Desk","price":"-12.50"}`, want: http.StatusBadRequest},
+		{name: "price too large", caller: "owner", body: `{"title":"Desk","price":"100000000"}`, want: http.StatusBadRequest},
+		{name: "admin", caller: "admin", want: http.StatusOK},
+		{name: "other seller", caller: "seller", want: http.StatusForbidden},
+		{name: "missing product", caller: "owner", missing: true, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServer(t)
+			tokens := map[string]string{
+				"owner":  ts.token(t, models.RoleSeller),
+				"seller": ts.token(t, models.RoleSeller),
+				"admin":  ts.token(t, models.RoleAdmin),
+			}
+			productID := ts.insertProduct(t, tokens["owner"], `{"title":"Lamp","price":10}`)
+			before, _ := repository.GetProductById(ctx, productID)
+			next := ts.listen(t)
+			path := "/product/" + productID
+			if tt.missing {
+				path = "/product/missing"
+			}
+
+			body := tt.body
+			if body == "" {
+				body = `{"title":"Desk","price":"12.50"}`
+			}
+			w := ts.do(http.MethodPut, path, tokens[tt.caller], body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			stored, _ := repository.GetProductById(ctx, productID)
+			if stored.UserId != before.UserId {
+				t.Errorf("owner changed from %s to %s", before.UserId, stored.UserId)
+			}
+			if tt.want != http.StatusOK {
+				if stored.Title != "Lamp" {
+					t.Errorf("stored title = %q after %d", stored.Title, w.Code)
+				}
+				ts.server.Hub().Broadcast(models.WebsocketMessage{Type: "sentinel"})
+				if message := next(); message.Type != "sentinel" {
+					t.Errorf("broadcast %s %s after %d", message.Type, message.Payload, w.Code)
+				}
+				return
+			}
+			if stored.Title != "Desk" || stored.Price != models.NewMoney(1250, "USD") {
+				t.Errorf("stored = %q %s", stored.Title, stored.Price)
+			}
+			message := next()
+			var payload models.Products
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if message.Type != ProductUpdatedMessage || payload.Id != productID || payload.Title != "Desk" || payload.UserId != before.UserId || payload.Created_at.IsZero() {
+				t.Errorf("broadcast %s %s, want the stored product", message.Type, message.Payload)
+			}
+		})
+	}
+}
+
+func TestDeleteProductHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		caller  string
+		missing bool
+		want    int
+	}{
+		{name: "owner", caller: "owner", want: http.StatusOK},
+		{name: "admin", caller: "admin", want: http.StatusOK},
+		{name: "other seller", caller: "seller", want: http.StatusForbidden},
+		{name: "missing product", caller: "owner", missing: true, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServer(t)
+			tokens := map[string]string{
+				"owner":  ts.token(t, models.RoleSeller),
+				"seller": ts.token(t, models.RoleSeller),
+				"admin":  ts.token(t, models.RoleAdmin),
+			}
+			productID := ts.insertProduct(t, tokens["owner"], `{"title":"Lamp","price":10}`)
+			next := ts.listen(t)
+			path := "/product/" + productID
+			if tt.missing {
+				path = "/product/missing"
+			}
+
+			w := ts.do(http.MethodDelete, path, tokens[tt.caller], "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.want)
+			}
+			stored, _ := repository.GetProductById(ctx, productID)
+			if deleted := stored == nil; deleted != (tt.want == http.StatusOK) {
+				t.Fatalf("product deleted = %v after %d", deleted, w.Code)
+			}
+			if tt.want != http.StatusOK {
+				ts.server.Hub().Broadcast(models.WebsocketMessage{Type: "sentinel"})
+				if message := next(); message.Type != "sentinel" {
+					t.Errorf("broadcast %s %s after %d", message.Type, message.Payload, w.Code)
+				}
+				return
+			}
+			message := next()
+			if message.Type != ProductDeletedMessage || !strings.Contains(string(message.Payload), productID) {
+				t.Errorf("broadcast %s %s", message.Type, message.Payload)
+			}
+		})
+	}
+}
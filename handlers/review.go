@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+type InsertReviewRequest struct {
+	Rating int    `json:"rating"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+func ListReviewsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := pageParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params := mux.Vars(r)
+		reviews, err := repository.ListReviews(r.Context(), params["id"], page)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reviews)
+	}
+}
+
+// InsertReviewHandler solo deja reseñar a quien recibió el producto en una
+// orden entregada; si la tienda tiene las órdenes deshabilitadas basta con
+// estar autenticado. Cada usuario reseña un producto una sola vez.
+func InsertReviewHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = InsertReviewRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.Title = strings.TrimSpace(request.Title)
+		request.Body = strings.TrimSpace(request.Body)
+		if err := validateReview(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		params := mux.Vars(r)
+		product, err := repository.GetProductById(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if product == nil {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		if product.UserId == claims.UserId {
+			http.Error(w, "you cannot review your own product", http.StatusForbidden)
+			return
+		}
+		if !s.Config().OrdersDisabled {
+			delivered, err := repository.HasDeliveredOrder(r.Context(), claims.UserId, product.Id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !delivered {
+				http.Error(w, "only customers who received this product can review it", http.StatusForbidden)
+				return
+			}
+		}
+
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		review := &models.Review{
+			Id:        id.String(),
+			ProductId: product.Id,
+			UserId:    claims.UserId,
+			Rating:    request.Rating,
+			Title:     request.Title,
+			Body:      request.Body,
+		}
+		err = repository.InsertReview(r.Context(), review)
+		if errors.Is(err, models.ErrDuplicateReview) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}
+}
+
+func validateReview(request InsertReviewRequest) error {
+	if request.Rating < 1 || request.Rating > 5 {
+		return errors.New("rating must be between 1 and 5")
+	}
+	if request.Title == "" {
+		return errors.New("title is required")
+	}
+	if utf8.RuneCountInString(request.Title) > 120 {
+		return errors.New("title must be at most 120 characters")
+	}
+	if utf8.RuneCountInString(request.Body) > 5000 {
+		return errors.New("body must be at most 5000 characters")
+	}
+	return nil
+}
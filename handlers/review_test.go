@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+func TestInsertReviewHandler(t *testing.T) {
+	ts := newTestServer(t)
+	seller := ts.token(t, models.RoleSeller)
+	id := ts.insertProduct(t, seller, `{"title":"Lamp","price":19.99}`)
+	path := "/product/" + id + "/reviews"
+
+	first := ts.token(t, models.RoleCustomer)
+	ts.mustDo(t, http.StatusForbidden, http.MethodPost, path, first, `{"rating":5,"title":"Great"}`)
+
+	// Sin órdenes basta con estar autenticado.
+	ts.server.Config().OrdersDisabled = true
+	ts.mustDo(t, http.StatusCreated, http.MethodPost, path, first, `{"rating":5,"title":"Great"}`)
+	ts.mustDo(t, http.StatusConflict, http.MethodPost, path, first, `{"rating":4,"title":"Again"}`)
+	ts.mustDo(t, http.StatusForbidden, http.MethodPost, path, seller, `{"rating":5,"title":"Mine"}`)
+	for _, rating := range []string{"4", "4"} {
+		ts.mustDo(t, http.StatusCreated, http.MethodPost, path, ts.token(t, models.RoleCustomer), `{"rating":`+rating+`,"title":"Good"}`)
+	}
+
+	summary, err := repository.GetReviewSummary(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.ReviewCount != 3 || summary.AverageRating != 4.33 {
+		t.Errorf("summary = %+v, want 3 reviews averaging 4.33", summary)
+	}
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+type UpsertShippingMethodRequest struct {
+	Name           string                    `json:"name"`
+	Type           models.ShippingMethodType `json:"type"`
+	Regions        []string                  `json:"regions"`
+	Price          models.Money              `json:"price"`
+	PerKg          models.Money              `json:"per_kg"`
+	FreeOver       models.Money              `json:"free_over"`
+	MaxWeightGrams int                       `json:"max_weight_grams"`
+}
+
+type ShippingQuoteResponse struct {
+	Destination string                  `json:"destination"`
+	WeightGrams int                     `json:"weight_grams"`
+	Methods     []*models.ShippingQuote `json:"methods"`
+}
+
+func ListShippingMethodsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		methods, err := repository.ListShippingMethods(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(methods)
+	}
+}
+
+func InsertShippingMethodHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertShippingMethodRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		method := &models.ShippingMethod{Id: id.String()}
+		request.apply(method)
+		if err := method.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repository.InsertShippingMethod(r.Context(), method); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(method)
+	}
+}
+
+// UpdateShippingMethodHandler no toca las órdenes ya hechas, que guardan una
+// copia del método.
+func UpdateShippingMethodHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertShippingMethodRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params := mux.Vars(r)
+		method, err := repository.GetShippingMethodById(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if method == nil {
+			http.Error(w, models.ErrShippingMethodNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		request.apply(method)
+		if err := method.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repository.UpdateShippingMethod(r.Context(), method); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(method)
+	}
+}
+
+func DeleteShippingMethodHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		err := repository.DeleteShippingMethod(r.Context(), params["id"])
+		if errors.Is(err, models.ErrShippingMethodNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Delete shipping method",
+		})
+	}
+}
+
+// ShippingQuoteHandler cotiza el carrito del usuario con todos los métodos que
+// llegan al destino, del más barato al más caro. El destino es una dirección
+// guardada (address_id), una región o la dirección de envío por defecto.
+func ShippingQuoteHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		region, err := taxRegion(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, destination, err := shippingDestination(r.Context(), claims.UserId, r.URL.Query().Get("address_id"), region)
+		if errors.Is(err, models.ErrAddressNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if destination == "" {
+			http.Error(w, "address_id or region is required when there is no default shipping address", http.StatusBadRequest)
+			return
+		}
+
+		cart, err := repository.GetCart(r.Context(), claims.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := priceCart(r.Context(), s, cart, destination); err != nil {
+			http.Error(w, err.Error(), currencyErrorStatus(err))
+			return
+		}
+		quotes, err := quoteShipping(r.Context(), cart, destination)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ShippingQuoteResponse{
+			Destination: destination,
+			WeightGrams: cart.WeightGrams,
+			Methods:     quotes,
+		})
+	}
+}
+
+func (request *UpsertShippingMethodRequest) apply(method *models.ShippingMethod) {
+	method.Name = strings.TrimSpace(request.Name)
+	method.Type = request.Type
+	method.Regions = []string{}
+	for _, region := range request.Regions {
+		method.Regions = append(method.Regions, models.NormalizeRegion(region))
+	}
+	method.Price = request.Price
+	method.PerKg = request.PerKg
+	method.FreeOver = request.FreeOver
+	method.MaxWeightGrams = request.MaxWeightGrams
+}
+
+// shippingDestination devuelve la dirección guardada y su región si hay
+// addressID; si no, la región recibida. Sin ninguna de las dos usa la
+// dirección de envío por defecto del usuario, si tiene. La dirección tiene que
+// ser del usuario.
+func shippingDestination(ctx context.Context, userID string, addressID string, region string) (*models.Address, string, error) {
+	if addressID == "" && region != "" {
+		return nil, region, nil
+	}
+	if addressID == "" {
+		address, _, err := defaultAddresses(ctx, userID)
+		if err != nil || address == nil {
+			return nil, "", err
+		}
+		return address, address.Destination(), nil
+	}
+	address, err := repository.GetAddressById(ctx, userID, addressID)
+	if err != nil {
+		return nil, "", err
+	}
+	if address == nil {
+		return nil, "", models.ErrAddressNotFound
+	}
+	return address, address.Destination(), nil
+}
+
+func quoteShipping(ctx context.Context, cart *models.Cart, destination string) ([]*models.ShippingQuote, error) {
+	methods, err := repository.ListShippingMethods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quotes := []*models.ShippingQuote{}
+	for _, method := range methods {
+		if quote, ok := method.Quote(cart, destination); ok {
+			quotes = append(quotes, quote)
+		}
+	}
+	slices.SortStableFunc(quotes, func(a, b *models.ShippingQuote) int {
+		return a.Cost.Cmp(b.Cost)
+	})
+	return quotes, nil
+}
+
+// applyShipping cotiza el método elegido hasta la dirección de envío guardada
+// y lo agrega al carrito; si no puede responde el error y devuelve false.
+func applyShipping(w http.ResponseWriter, r *http.Request, cart *models.Cart, methodID string, address *models.Address) bool {
+	if address == nil {
+		http.Error(w, "address_id or a default shipping address is required to ship the order", http.StatusBadRequest)
+		return false
+	}
+	method, err := repository.GetShippingMethodById(r.Context(), methodID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if method == nil {
+		http.Error(w, models.ErrShippingMethodNotFound.Error(), http.StatusNotFound)
+		return false
+	}
+	quote, ok := method.Quote(cart, address.Destination())
+	if !ok {
+		http.Error(w, models.ErrShippingUnavailable.Error(), http.StatusConflict)
+		return false
+	}
+	cart.ApplyShipping(quote)
+	return true
+}
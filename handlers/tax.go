@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/cristiangar0398/ShopAPI/tax"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+type UpsertTaxRuleRequest struct {
+	Region     string `json:"region"`
+	CategoryId string `json:"category_id"`
+	TaxClass   string `json:"tax_class"`
+	RateBps    int    `json:"rate_bps"`
+	Inclusive  bool   `json:"inclusive"`
+}
+
+func ListTaxRulesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rules, err := repository.ListTaxRules(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rules)
+	}
+}
+
+func InsertTaxRuleHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertTaxRuleRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rule := &models.TaxRule{Id: id.String()}
+		request.apply(rule)
+		if !validateTaxRule(w, r, rule) {
+			return
+		}
+
+		if err := repository.InsertTaxRule(r.Context(), rule); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(rule)
+	}
+}
+
+func UpdateTaxRuleHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpsertTaxRuleRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params := mux.Vars(r)
+		rule, err := repository.GetTaxRuleById(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if rule == nil {
+			http.Error(w, models.ErrTaxRuleNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		request.apply(rule)
+		if !validateTaxRule(w, r, rule) {
+			return
+		}
+
+		if err := repository.UpdateTaxRule(r.Context(), rule); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rule)
+	}
+}
+
+func DeleteTaxRuleHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		err := repository.DeleteTaxRule(r.Context(), params["id"])
+		if errors.Is(err, models.ErrTaxRuleNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Delete tax rule",
+		})
+	}
+}
+
+func (request *UpsertTaxRuleRequest) apply(rule *models.TaxRule) {
+	rule.Region = models.NormalizeRegion(request.Region)
+	rule.CategoryId = request.CategoryId
+	rule.TaxClass = models.NormalizeTaxClass(request.TaxClass)
+	rule.RateBps = request.RateBps
+	rule.Inclusive = request.Inclusive
+}
+
+func validateTaxRule(w http.ResponseWriter, r *http.Request, rule *models.TaxRule) bool {
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if rule.CategoryId == "" {
+		return true
+	}
+	category, err := repository.GetCategoryById(r.Context(), rule.CategoryId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if category == nil {
+		http.Error(w, "category not found", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// taxRegion lee la región de impuestos del parámetro region. Solo sirve para
+// ver el carrito; sin región el carrito no lleva impuestos.
+func taxRegion(r *http.Request) (string, error) {
+	region := models.NormalizeRegion(r.URL.Query().Get("region"))
+	if region != "" && !models.ValidRegion(region) {
+		return "", errors.New("region must be an ISO country code, optionally followed by a subdivision (US-CA)")
+	}
+	return region, nil
+}
+
+// applyTaxes calcula el impuesto de cada item sobre su subtotal ya descontado
+// el cupón. Las reglas por categoría aplican también a las subcategorías.
+func applyTaxes(ctx context.Context, s server.Server, cart *models.Cart, region string) error {
+	if region == "" {
+		cart.ApplyTaxes("", nil)
+		return nil
+	}
+	categories, err := repository.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+	request := &tax.Request{Region: region}
+	for i, amount := range cart.TaxableAmounts() {
+		item := cart.Items[i]
+		productCategories, err := repository.ListProductCategories(ctx, item.ProductId)
+		if err != nil {
+			return err
+		}
+		categoryIds := []string{}
+		for _, category := range productCategories {
+			categoryIds = append(categoryIds, models.AncestorIds(categories, category.Id)...)
+		}
+		request.Lines = append(request.Lines, tax.Line{
+			ProductId:   item.ProductId,
+			TaxClass:    item.TaxClass,
+			CategoryIds: categoryIds,
+			Amount:      amount,
+		})
+	}
+	lines, err := s.Taxes().Calculate(ctx, request)
+	if err != nil {
+		return err
+	}
+	cart.ApplyTaxes(region, lines)
+	return nil
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/golang-jwt/jwt"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	accessTokenDuration  = 15 * time.Minute
+	refreshTokenDuration = 30 * 24 * time.Hour
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func RefreshTokenHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = RefreshTokenRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		stored, err := repository.GetRefreshToken(r.Context(), hashRefreshToken(request.RefreshToken))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if stored == nil || stored.Expires_at.Before(time.Now()) {
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+			return
+		}
+
+		err = repository.RevokeRefreshToken(r.Context(), stored.Hash)
+		if errors.Is(err, models.ErrRefreshTokenRevoked) || stored.Revoked {
+			// Un token ya rotado se volvió a usar: puede haber sido robado, así
+			// que se cierra toda la sesión.
+			if err := repository.RevokeRefreshTokens(r.Context(), stored.UserId, stored.FamilyId); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			http.Error(w, models.ErrRefreshTokenRevoked.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		user, err := repository.GetUserById(r.Context(), stored.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+			return
+		}
+
+		response, err := issueTokens(r.Context(), s, user, stored.FamilyId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+func LogoutHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = RefreshTokenRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if claims.Id != "" {
+			if err := repository.RevokeToken(r.Context(), claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Sin refresh token se cierran todas las sesiones del usuario.
+		familyID := ""
+		if request.RefreshToken != "" {
+			stored, err := repository.GetRefreshToken(r.Context(), hashRefreshToken(request.RefreshToken))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if stored == nil || stored.UserId != claims.UserId {
+				http.Error(w, "invalid refresh token", http.StatusBadRequest)
+				return
+			}
+			familyID = stored.FamilyId
+		}
+		if err := repository.RevokeRefreshTokens(r.Context(), claims.UserId, familyID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Logged out",
+		})
+	}
+}
+
+// issueTokens firma un access token de vida corta y guarda un refresh token
+// nuevo. Con familyID vacío se abre una sesión nueva.
+func issueTokens(ctx context.Context, s server.Server, user *models.User, familyID string) (*LoginResponse, error) {
+	jti, err := ksuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(accessTokenDuration)
+	claims := models.AppClaims{
+		UserId: user.Id,
+		Role:   user.Role,
+		StandardClaims: jwt.StandardClaims{
+			Id:        jti.String(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(s.Config().JWTSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if familyID == "" {
+		familyID = jti.String()
+	}
+	err = repository.InsertRefreshToken(ctx, &models.RefreshToken{
+		Hash:       hashRefreshToken(refreshToken),
+		UserId:     user.Id,
+		FamilyId:   familyID,
+		Expires_at: time.Now().Add(refreshTokenDuration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenDuration.Seconds()),
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -4,17 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"strconv"
-	"time"
 
 	"github.com/cristiangar0398/ShopAPI/middleware"
 	"github.com/cristiangar0398/ShopAPI/models"
 	"github.com/cristiangar0398/ShopAPI/repository"
 	"github.com/cristiangar0398/ShopAPI/server"
-	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
 	"github.com/segmentio/ksuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -30,12 +28,19 @@ type SignUpRequest struct {
 }
 
 type SignUpResponse struct {
-	Id    string `json:"id"`
-	Email string `json:"email"`
+	Id    string      `json:"id"`
+	Email string      `json:"email"`
+	Role  models.Role `json:"role"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role models.Role `json:"role"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 }
 
 func SignUpHandler(s server.Server) http.HandlerFunc {
@@ -46,7 +51,6 @@ func SignUpHandler(s server.Server) http.HandlerFunc {
 			http.Error(w, "Invalid request payload", http.StatusBadRequest)
 			return
 		}
-
 		userID, err := generateUserID()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -63,6 +67,7 @@ func SignUpHandler(s server.Server) http.HandlerFunc {
 		json.NewEncoder(w).Encode(SignUpResponse{
 			Id:    user.Id,
 			Email: user.Email,
+			Role:  user.Role,
 		})
 	}
 }
@@ -86,7 +91,7 @@ func createUser(ctx context.Context, request SignUpRequest, userID string) (*mod
 	hashCostStr := os.Getenv("HASH_COST")
 	hashCost, err := strconv.Atoi(hashCostStr)
 	if err != nil {
-		return nil, fmt.Errorf("Error al convertir HASH_COST a entero:", err)
+		return nil, fmt.Errorf("Error al convertir HASH_COST a entero: %w", err)
 	}
 
 	//logica de creacion de usuario
@@ -100,10 +105,12 @@ func createUser(ctx context.Context, request SignUpRequest, userID string) (*mod
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), hashCost)
 
+	// Todos se registran como clientes; otros roles los asigna un admin.
 	user := &models.User{
 		Email:    request.Email,
 		Password: string(hashedPassword),
 		Id:       userID,
+		Role:     models.RoleCustomer,
 	}
 	err = repository.InsertUser(ctx, user)
 	return user, err
@@ -133,6 +140,7 @@ func LoginHandler(s server.Server) http.HandlerFunc {
 
 		if user == nil {
 			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
 		}
 
 		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
@@ -141,32 +149,30 @@ func LoginHandler(s server.Server) http.HandlerFunc {
 			return
 		}
 
-		claims := models.AppClaims{
-			UserId: user.Id,
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: time.Now().Add(2 * time.Hour * 24).Unix(),
-			},
-		}
-
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString([]byte(s.Config().JWTSecret))
+		response, err := issueTokens(r.Context(), s, user, "")
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
 		w.Header().Set("content-type", "aaplication/json")
-		json.NewEncoder(w).Encode(LoginResponse{
-			Token: tokenString,
-		})
+		json.NewEncoder(w).Encode(response)
 	}
 }
 
+// MeResponse agrega al usuario sus direcciones por defecto; son null si no
+// tiene.
+type MeResponse struct {
+	*models.User
+	DefaultShippingAddress *models.Address `json:"default_shipping_address"`
+	DefaultBillingAddress  *models.Address `json:"default_billing_address"`
+}
+
 func MeHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token, err := middleware.TokenParseString(w, s, r)
 		if err != nil {
-			log.Fatal(err)
+			return
 		}
 
 		if claims, ok := token.Claims.(*models.AppClaims); ok && token.Valid {
@@ -175,11 +181,58 @@ func MeHandler(s server.Server) http.HandlerFunc {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+			if user == nil {
+				http.Error(w, "user not found", http.StatusNotFound)
+				return
+			}
+			shipping, billing, err := defaultAddresses(r.Context(), user.Id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
 			w.Header().Set("content-type", "aaplication/json")
-			json.NewEncoder(w).Encode(user)
+			json.NewEncoder(w).Encode(MeResponse{
+				User:                   user,
+				DefaultShippingAddress: shipping,
+				DefaultBillingAddress:  billing,
+			})
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 	}
 }
+
+func UpdateUserRoleHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request = UpdateUserRoleRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !request.Role.Valid() {
+			http.Error(w, "unknown role", http.StatusBadRequest)
+			return
+		}
+
+		params := mux.Vars(r)
+		user, err := repository.GetUserById(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		if err := repository.UpdateUserRole(r.Context(), user.Id, request.Role); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Role updated",
+		})
+	}
+}
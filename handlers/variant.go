@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+type ProductOptionsRequest struct {
+	Options []*models.ProductOption `json:"options"`
+}
+
+// UpsertVariantRequest guarda el precio sin leer porque está en la moneda del
+// producto, que se conoce después de cargarlo.
+type UpsertVariantRequest struct {
+	Sku     string            `json:"sku"`
+	Price   json.RawMessage   `json:"price"`
+	Stock   int               `json:"stock"`
+	Options map[string]string `json:"options"`
+}
+
+type ProductOptionsResponse struct {
+	Options  []*models.ProductOption  `json:"options"`
+	Variants []*models.ProductVariant `json:"variants"`
+}
+
+func ListVariantsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		product, err := repository.GetProductById(r.Context(), params["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if product == nil {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		detail, err := productDetail(r, product)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ProductOptionsResponse{Options: detail.Options, Variants: detail.Variants})
+	}
+}
+
+// SetProductOptionsHandler reemplaza las opciones del producto. Si alguna
+// variante existente deja de encajar con las nuevas opciones se rechaza.
+func SetProductOptionsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = ProductOptionsRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		for _, option := range request.Options {
+			if option != nil {
+				option.Name = strings.TrimSpace(option.Name)
+			}
+		}
+		if err := models.ValidateProductOptions(request.Options); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		variants, err := repository.ListVariants(r.Context(), product.Id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, variant := range variants {
+			if err := models.ValidateVariantOptions(request.Options, variant.Options); err != nil {
+				http.Error(w, "variant "+variant.Sku+": "+err.Error(), http.StatusConflict)
+				return
+			}
+		}
+
+		if err := repository.SetProductOptions(r.Context(), product.Id, request.Options); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(request.Options)
+	}
+}
+
+func InsertVariantHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = UpsertVariantRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		id, err := ksuid.NewRandom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		variant := &models.ProductVariant{Id: id.String(), ProductId: product.Id}
+		if err := applyVariantRequest(variant, request, product.Currency); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if status, err := validateVariant(r, variant); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		err = repository.InsertVariant(r.Context(), variant)
+		if errors.Is(err, models.ErrDuplicateSku) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(variant)
+	}
+}
+
+func UpdateVariantHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = UpsertVariantRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		variant, ok := productVariant(w, r, product)
+		if !ok {
+			return
+		}
+		if err := applyVariantRequest(variant, request, product.Currency); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if status, err := validateVariant(r, variant); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		err := repository.UpdateVariant(r.Context(), variant)
+		if errors.Is(err, models.ErrDuplicateSku) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(variant)
+	}
+}
+
+func DeleteVariantHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		product, ok := editableProduct(w, r, claims)
+		if !ok {
+			return
+		}
+		variant, ok := productVariant(w, r, product)
+		if !ok {
+			return
+		}
+		if err := repository.DeleteVariant(r.Context(), product.Id, variant.Id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PostUpdateResponse{
+			Message: "Delete variant",
+		})
+	}
+}
+
+// productDetail junta el producto con sus opciones, variantes, imágenes y
+// reseñas.
+func productDetail(r *http.Request, product *models.Products) (*models.ProductDetail, error) {
+	options, err := repository.ListProductOptions(r.Context(), product.Id)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := repository.ListVariants(r.Context(), product.Id)
+	if err != nil {
+		return nil, err
+	}
+	images, err := repository.ListProductImages(r.Context(), product.Id)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := repository.GetReviewSummary(r.Context(), product.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ProductDetail{Products: *product, ReviewSummary: *summary, Options: options, Variants: variants, Images: images}, nil
+}
+
+// productVariant carga la variante de la ruta y comprueba que pertenezca al
+// producto.
+func productVariant(w http.ResponseWriter, r *http.Request, product *models.Products) (*models.ProductVariant, bool) {
+	params := mux.Vars(r)
+	variant, err := repository.GetVariantById(r.Context(), params["variantId"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if variant == nil || variant.ProductId != product.Id {
+		http.Error(w, "variant not found", http.StatusNotFound)
+		return nil, false
+	}
+	return variant, true
+}
+
+func applyVariantRequest(variant *models.ProductVariant, request UpsertVariantRequest, currency string) error {
+	variant.Sku = strings.TrimSpace(request.Sku)
+	variant.Price = nil
+	if len(request.Price) > 0 && string(request.Price) != "null" {
+		price := models.NewMoney(0, currency)
+		if err := price.UnmarshalJSON(request.Price); err != nil {
+			return err
+		}
+		variant.Price = &price
+	}
+	variant.Stock = request.Stock
+	variant.Options = request.Options
+	if variant.Options == nil {
+		variant.Options = map[string]string{}
+	}
+	return nil
+}
+
+// validateVariant revisa los campos y que la combinación de opciones sea
+// válida y no esté repetida en otra variante del mismo producto.
+func validateVariant(r *http.Request, variant *models.ProductVariant) (int, error) {
+	if variant.Sku == "" {
+		return http.StatusBadRequest, errors.New("sku is required")
+	}
+	if variant.Price != nil {
+		if err := validatePrice(*variant.Price); err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+	if variant.Stock < 0 {
+		return http.StatusBadRequest, errors.New("stock must not be negative")
+	}
+
+	options, err := repository.ListProductOptions(r.Context(), variant.ProductId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if err := models.ValidateVariantOptions(options, variant.Options); err != nil {
+		return http.StatusBadRequest, err
+	}
+	variants, err := repository.ListVariants(r.Context(), variant.ProductId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if slices.ContainsFunc(variants, func(v *models.ProductVariant) bool {
+		return v.Id != variant.Id && models.SameOptions(v.Options, variant.Options)
+	}) {
+		return http.StatusConflict, models.ErrDuplicateVariant
+	}
+	return 0, nil
+}
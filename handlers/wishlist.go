@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/server"
+	"github.com/gorilla/mux"
+)
+
+type AddWishlistItemRequest struct {
+	ProductId string `json:"product_id"`
+}
+
+func GetWishlistHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		writeWishlist(w, r, claims.UserId)
+	}
+}
+
+func AddWishlistItemHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		var request = AddWishlistItemRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		product, err := repository.GetProductById(r.Context(), request.ProductId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if product == nil {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+
+		if err := repository.AddWishlistItem(r.Context(), claims.UserId, product.Id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeWishlist(w, r, claims.UserId)
+	}
+}
+
+func DeleteWishlistItemHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		params := mux.Vars(r)
+		err := repository.DeleteWishlistItem(r.Context(), claims.UserId, params["productId"])
+		if errors.Is(err, models.ErrWishlistItemNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeWishlist(w, r, claims.UserId)
+	}
+}
+
+func ClearWishlistHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := appClaims(w, s, r)
+		if !ok {
+			return
+		}
+		if err := repository.ClearWishlist(r.Context(), claims.UserId); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeWishlist(w, r, claims.UserId)
+	}
+}
+
+func writeWishlist(w http.ResponseWriter, r *http.Request, userID string) {
+	items, err := repository.ListWishlist(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
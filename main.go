@@ -5,9 +5,11 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/cristiangar0398/ShopAPI/handlers"
 	"github.com/cristiangar0398/ShopAPI/middleware"
+	"github.com/cristiangar0398/ShopAPI/models"
 	"github.com/cristiangar0398/ShopAPI/server"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -29,11 +31,34 @@ func main() {
 	DATABASE_URL := os.Getenv("DATABASE_URL")
 	STATIC_PORT := os.Getenv("STATIC_PORT")
 	STATIC_DIR := os.Getenv("STATIC_DIR")
+	PUBLIC_URL := os.Getenv("PUBLIC_URL")
+	STATIC_URL := os.Getenv("STATIC_URL")
+	if STATIC_URL == "" {
+		STATIC_URL = "http://localhost" + STATIC_PORT
+	}
+	IN_MEMORY := os.Getenv("IN_MEMORY") == "true"
+	PAYMENT_PROVIDER_URL := os.Getenv("PAYMENT_PROVIDER_URL")
+	PAYMENT_WEBHOOK_SECRET := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	PAYMENT_STUB_PORT := os.Getenv("PAYMENT_STUB_PORT")
+	// Con ORDERS_DISABLED=true la tienda no vende por órdenes y las reseñas
+	// no exigen una orden entregada.
+	ORDERS_DISABLED := os.Getenv("ORDERS_DISABLED") == "true"
+	RATES_FILE := os.Getenv("RATES_FILE")
+	ALLOWED_ORIGINS := os.Getenv("ALLOWED_ORIGINS")
 
 	s, err := server.NewServer(context.Background(), &server.Config{
-		Port:        PORT,
-		JWTSecret:   JMT_SECRET,
-		BatabaseUrl: DATABASE_URL,
+		Port:                 PORT,
+		JWTSecret:            JMT_SECRET,
+		BatabaseUrl:          DATABASE_URL,
+		InMemory:             IN_MEMORY,
+		PaymentProviderUrl:   PAYMENT_PROVIDER_URL,
+		PaymentWebhookSecret: PAYMENT_WEBHOOK_SECRET,
+		StaticDir:            STATIC_DIR,
+		StaticUrl:            STATIC_URL,
+		PublicUrl:            strings.TrimSuffix(PUBLIC_URL, "/"),
+		OrdersDisabled:       ORDERS_DISABLED,
+		RatesFile:            RATES_FILE,
+		AllowedOrigins:       strings.Split(ALLOWED_ORIGINS, ","),
 	})
 
 	if err != nil {
@@ -41,6 +66,9 @@ func main() {
 	}
 
 	go s.StartStaticFileServer(STATIC_PORT, STATIC_DIR)
+	if PAYMENT_STUB_PORT != "" {
+		go s.StartPaymentStub(PAYMENT_STUB_PORT)
+	}
 	s.Start(BindRoutes)
 
 }
@@ -53,11 +81,88 @@ func BindRoutes(s server.Server, r *mux.Router) {
 	r.HandleFunc("/signup", handlers.SignUpHandler(s)).Methods(http.MethodPost)
 	r.HandleFunc("/login", handlers.LoginHandler(s)).Methods(http.MethodPost)
 	r.HandleFunc("/me", handlers.MeHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/me/wishlist", handlers.GetWishlistHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/me/wishlist", handlers.AddWishlistItemHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/me/wishlist", handlers.ClearWishlistHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/me/wishlist/{productId}", handlers.DeleteWishlistItemHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/me/addresses", handlers.ListAddressesHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/me/addresses", handlers.InsertAddressHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/me/addresses/{id}", handlers.GetAddressHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/me/addresses/{id}", handlers.UpdateAddressHandler(s)).Methods(http.MethodPut)
+	r.HandleFunc("/me/addresses/{id}", handlers.DeleteAddressHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/token/refresh", handlers.RefreshTokenHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/logout", handlers.LogoutHandler(s)).Methods(http.MethodPost)
+
+	sellers := middleware.RequireRoles(s, models.RoleSeller, models.RoleAdmin)
+	admins := middleware.RequireRoles(s, models.RoleAdmin)
 
-	r.HandleFunc("/product", handlers.InsertProducttHandler(s)).Methods(http.MethodPost)
+	r.Handle("/users/{id}/role", admins(handlers.UpdateUserRoleHandler(s))).Methods(http.MethodPut)
+
+	r.Handle("/product", sellers(handlers.InsertProducttHandler(s))).Methods(http.MethodPost)
+	r.HandleFunc("/product/search", handlers.SearchProductHandler(s)).Methods(http.MethodGet)
+	r.Handle("/product/import", sellers(handlers.ImportProductsHandler(s))).Methods(http.MethodPost)
+	r.Handle("/product/export", sellers(handlers.ExportProductsHandler(s))).Methods(http.MethodGet)
 	r.HandleFunc("/product/{id}", handlers.GetProductByIdHandler(s)).Methods(http.MethodGet)
-	r.HandleFunc("/product/{id}", handlers.UpdateProducttHandler(s)).Methods(http.MethodPut)
-	r.HandleFunc("/product/{id}", handlers.DeleteProductHandler(s)).Methods(http.MethodDelete)
+	r.Handle("/product/{id}", sellers(handlers.UpdateProducttHandler(s))).Methods(http.MethodPut)
+	r.Handle("/product/{id}", sellers(handlers.DeleteProductHandler(s))).Methods(http.MethodDelete)
+	r.HandleFunc("/product/{id}/categories", handlers.ListProductCategoriesHandler(s)).Methods(http.MethodGet)
+	r.Handle("/product/{id}/categories", sellers(handlers.SetProductCategoriesHandler(s))).Methods(http.MethodPut)
+	r.Handle("/product/{id}/inventory", sellers(handlers.GetInventoryHandler(s))).Methods(http.MethodGet)
+	r.Handle("/product/{id}/inventory", sellers(handlers.SetInventoryHandler(s))).Methods(http.MethodPut)
+	r.HandleFunc("/product/{id}/images", handlers.ListProductImagesHandler(s)).Methods(http.MethodGet)
+	r.Handle("/product/{id}/images", sellers(handlers.UploadProductImagesHandler(s))).Methods(http.MethodPost)
+	r.Handle("/product/{id}/images/order", sellers(handlers.ReorderProductImagesHandler(s))).Methods(http.MethodPut)
+	r.Handle("/product/{id}/images/{imageId}", sellers(handlers.DeleteProductImageHandler(s))).Methods(http.MethodDelete)
+	r.HandleFunc("/product/{id}/reviews", handlers.ListReviewsHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/product/{id}/reviews", handlers.InsertReviewHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/product/{id}/variants", handlers.ListVariantsHandler(s)).Methods(http.MethodGet)
+	r.Handle("/product/{id}/variants", sellers(handlers.InsertVariantHandler(s))).Methods(http.MethodPost)
+	r.Handle("/product/{id}/variants/options", sellers(handlers.SetProductOptionsHandler(s))).Methods(http.MethodPut)
+	r.Handle("/product/{id}/variants/{variantId}", sellers(handlers.UpdateVariantHandler(s))).Methods(http.MethodPut)
+	r.Handle("/product/{id}/variants/{variantId}", sellers(handlers.DeleteVariantHandler(s))).Methods(http.MethodDelete)
 	r.HandleFunc("/product", handlers.ListProductHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/currency/rates", handlers.GetRatesHandler(s)).Methods(http.MethodGet)
+	r.Handle("/currency/rates", admins(handlers.SetRatesHandler(s))).Methods(http.MethodPut)
+	r.HandleFunc("/feed.xml", handlers.ProductFeedHandler(s)).Methods(http.MethodGet, http.MethodHead)
+
+	r.HandleFunc("/categories", handlers.ListCategoriesHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/categories/{id}", handlers.GetCategoryByIdHandler(s)).Methods(http.MethodGet)
+	r.Handle("/categories", admins(handlers.InsertCategoryHandler(s))).Methods(http.MethodPost)
+	r.Handle("/categories/{id}", admins(handlers.UpdateCategoryHandler(s))).Methods(http.MethodPut)
+	r.Handle("/categories/{id}", admins(handlers.DeleteCategoryHandler(s))).Methods(http.MethodDelete)
+
+	r.HandleFunc("/cart", handlers.GetCartHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/cart/items", handlers.AddCartItemHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/cart/items/{productId}", handlers.UpdateCartItemHandler(s)).Methods(http.MethodPatch)
+	r.HandleFunc("/cart/items/{productId}", handlers.DeleteCartItemHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/cart/coupon", handlers.ApplyCartCouponHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/cart/coupon", handlers.RemoveCartCouponHandler(s)).Methods(http.MethodDelete)
+	r.HandleFunc("/cart/shipping", handlers.ShippingQuoteHandler(s)).Methods(http.MethodGet)
+
+	r.Handle("/coupons", admins(handlers.ListCouponsHandler(s))).Methods(http.MethodGet)
+	r.Handle("/coupons", admins(handlers.InsertCouponHandler(s))).Methods(http.MethodPost)
+	r.Handle("/coupons/{code}", admins(handlers.GetCouponHandler(s))).Methods(http.MethodGet)
+	r.Handle("/coupons/{code}", admins(handlers.UpdateCouponHandler(s))).Methods(http.MethodPut)
+	r.Handle("/coupons/{code}", admins(handlers.DeleteCouponHandler(s))).Methods(http.MethodDelete)
+
+	r.Handle("/tax/rules", admins(handlers.ListTaxRulesHandler(s))).Methods(http.MethodGet)
+	r.Handle("/tax/rules", admins(handlers.InsertTaxRuleHandler(s))).Methods(http.MethodPost)
+	r.Handle("/tax/rules/{id}", admins(handlers.UpdateTaxRuleHandler(s))).Methods(http.MethodPut)
+	r.Handle("/tax/rules/{id}", admins(handlers.DeleteTaxRuleHandler(s))).Methods(http.MethodDelete)
+
+	r.Handle("/shipping/methods", admins(handlers.ListShippingMethodsHandler(s))).Methods(http.MethodGet)
+	r.Handle("/shipping/methods", admins(handlers.InsertShippingMethodHandler(s))).Methods(http.MethodPost)
+	r.Handle("/shipping/methods/{id}", admins(handlers.UpdateShippingMethodHandler(s))).Methods(http.MethodPut)
+	r.Handle("/shipping/methods/{id}", admins(handlers.DeleteShippingMethodHandler(s))).Methods(http.MethodDelete)
+
+	r.HandleFunc("/orders", handlers.PlaceOrderHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/orders", handlers.ListOrdersHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/orders/{id}", handlers.GetOrderByIdHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/orders/{id}/status", handlers.UpdateOrderStatusHandler(s)).Methods(http.MethodPatch)
+	r.HandleFunc("/orders/{id}/pay", handlers.PayOrderHandler(s)).Methods(http.MethodPost)
+	r.HandleFunc("/orders/{id}/payments", handlers.ListOrderPaymentsHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/payments/webhook", handlers.PaymentWebhookHandler(s)).Methods(http.MethodPost)
+
+	r.HandleFunc("/ws", s.Hub().HandleWebSocket)
 
 }
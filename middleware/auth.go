@@ -2,15 +2,20 @@ package middleware
 
 import (
 	"errors"
-	"log"
 	"net/http"
 	"strings"
 
 	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/repository"
 	"github.com/cristiangar0398/ShopAPI/server"
 	"github.com/golang-jwt/jwt"
 )
 
+var (
+	ErrInvalidToken = errors.New("token is not valid")
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
 var (
 	NO_AUTH_NEEDED = []string{
 		"login",
@@ -40,7 +45,7 @@ func CheckAuthMiddleware(s server.Server) func(h http.Handler) http.Handler {
 
 			_, err := TokenParseString(w, s, r)
 			if err != nil {
-				log.Fatal(err)
+				return
 			}
 
 			next.ServeHTTP(w, r)
@@ -64,8 +69,41 @@ func TokenParseString(w http.ResponseWriter, s server.Server, r *http.Request) (
 	}
 
 	if !token.Valid {
-		return nil, errors.New("token is not valid")
+		http.Error(w, ErrInvalidToken.Error(), http.StatusUnauthorized)
+		return nil, ErrInvalidToken
+	}
+
+	if claims, ok := token.Claims.(*models.AppClaims); ok && claims.Id != "" {
+		revoked, err := repository.IsTokenRevoked(r.Context(), claims.Id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil, err
+		}
+		if revoked {
+			http.Error(w, ErrTokenRevoked.Error(), http.StatusUnauthorized)
+			return nil, ErrTokenRevoked
+		}
 	}
 
 	return token, nil
 }
+
+// RequireRoles deja pasar solo a los usuarios cuyo token tenga alguno de los
+// roles indicados. Se declara por ruta en BindRoutes.
+func RequireRoles(s server.Server, roles ...models.Role) func(h http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := TokenParseString(w, s, r)
+			if err != nil {
+				return
+			}
+			claims, ok := token.Claims.(*models.AppClaims)
+			if !ok || !claims.HasRole(roles...) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+
+var subdivisionPattern = regexp.MustCompile(`^[A-Z0-9]{1,3}$`)
+
+// Address es una dirección de la libreta del usuario. Country es el código ISO
+// de dos letras y Region la subdivisión (el estado o la provincia) cuando el
+// país la usa. Cada usuario tiene a lo sumo una dirección de envío y una de
+// facturación por defecto.
+type Address struct {
+	Id              string    `json:"id"`
+	UserId          string    `json:"userId"`
+	Name            string    `json:"name"`
+	Line1           string    `json:"line1"`
+	Line2           string    `json:"line2,omitempty"`
+	City            string    `json:"city"`
+	Region          string    `json:"region,omitempty"`
+	PostalCode      string    `json:"postal_code,omitempty"`
+	Country         string    `json:"country"`
+	Phone           string    `json:"phone,omitempty"`
+	DefaultShipping bool      `json:"default_shipping"`
+	DefaultBilling  bool      `json:"default_billing"`
+	Created_at      time.Time `json:"created_at"`
+	Updated_at      time.Time `json:"updated_at"`
+}
+
+// Normalize recorta los espacios y pasa a mayúsculas los códigos.
+func (a *Address) Normalize() {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.ToUpper(strings.TrimSpace(a.Region))
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Phone = strings.TrimSpace(a.Phone)
+}
+
+// Validate revisa los campos comunes y después los que exige el país.
+func (a *Address) Validate() error {
+	if !ValidCountry(a.Country) {
+		return errors.New("country must be an ISO 3166-1 alpha-2 code")
+	}
+	if a.Name == "" || a.Line1 == "" || a.City == "" {
+		return errors.New("name, line1 and city are required")
+	}
+	if len(a.Name) > 120 || len(a.Line1) > 225 || len(a.Line2) > 225 || len(a.City) > 120 || len(a.PostalCode) > 16 || len(a.Phone) > 32 {
+		return errors.New("address fields are too long")
+	}
+	if a.Region != "" && !subdivisionPattern.MatchString(a.Region) {
+		return errors.New("region must be the subdivision code, for example CA")
+	}
+
+	rule, ok := addressRules[a.Country]
+	if !ok {
+		return nil
+	}
+	if rule.region && a.Region == "" {
+		return fmt.Errorf("region is required for %s addresses", a.Country)
+	}
+	if rule.postalCode != nil && !rule.postalCode.MatchString(a.PostalCode) {
+		return fmt.Errorf("postal_code is not valid for %s addresses", a.Country)
+	}
+	return nil
+}
+
+// Destination devuelve la región de la dirección con el mismo formato que
+// usan las reglas de impuestos y envío: "ES" o "US-CA".
+func (a *Address) Destination() string {
+	if a.Region == "" {
+		return a.Country
+	}
+	return a.Country + "-" + a.Region
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxCartItemQuantity es el tope de unidades de un producto en el carrito,
+// sumando todas las veces que se agrega.
+const MaxCartItemQuantity = 100
+
+var (
+	ErrCartItemNotFound = errors.New("cart item not found")
+	ErrCartItemQuantity = errors.New("quantity must be between 1 and 100")
+)
+
+// CartItem es un producto o, si el producto tiene variantes, una variante
+// concreta; el precio es el de la variante cuando lo tiene.
+type CartItem struct {
+	ProductId   string   `json:"product_id"`
+	VariantId   string   `json:"variant_id,omitempty"`
+	Sku         string   `json:"sku,omitempty"`
+	Title       string   `json:"title"`
+	Price       Money    `json:"price"`
+	Quantity    int      `json:"quantity"`
+	Subtotal    Money    `json:"subtotal"`
+	TaxClass    string   `json:"-"`
+	WeightGrams int      `json:"-"`
+	Tax         *TaxLine `json:"tax,omitempty"`
+}
+
+// Cart lleva el cupón aplicado; Discount y FreeShipping los calcula el handler
+// al revisar el cupón y CouponError explica por qué ya no aplica. Los
+// impuestos dependen de la región y también los fija el handler, igual que el
+// envío elegido al hacer el pedido.
+type Cart struct {
+	UserId       string         `json:"user_id"`
+	Items        []*CartItem    `json:"items"`
+	TotalItems   int            `json:"total_items"`
+	WeightGrams  int            `json:"weight_grams"`
+	Subtotal     Money          `json:"subtotal"`
+	CouponCode   string         `json:"coupon_code,omitempty"`
+	CouponError  string         `json:"coupon_error,omitempty"`
+	Discount     Money          `json:"discount"`
+	FreeShipping bool           `json:"free_shipping"`
+	TaxRegion    string         `json:"tax_region,omitempty"`
+	Tax          Money          `json:"tax"`
+	Shipping     *ShippingQuote `json:"shipping,omitempty"`
+	Total        Money          `json:"total"`
+	Updated_at   time.Time      `json:"updated_at"`
+}
+
+// CalculateTotals recalcula los subtotales con el precio actual de cada
+// producto. Tax suma todos los impuestos pero al total solo se agregan los que
+// no vienen incluidos en el precio.
+func (c *Cart) CalculateTotals() {
+	c.TotalItems = 0
+	c.WeightGrams = 0
+	c.Subtotal = NewMoney(0, DefaultCurrency)
+	c.Tax = NewMoney(0, DefaultCurrency)
+	exclusive := NewMoney(0, DefaultCurrency)
+	for _, item := range c.Items {
+		item.Subtotal = item.Price.Mul(int64(item.Quantity))
+		c.TotalItems += item.Quantity
+		c.WeightGrams += item.WeightGrams * item.Quantity
+		c.Subtotal = c.Subtotal.Add(item.Subtotal)
+		if item.Tax != nil {
+			c.Tax = c.Tax.Add(item.Tax.Amount)
+			if !item.Tax.Inclusive {
+				exclusive = exclusive.Add(item.Tax.Amount)
+			}
+		}
+	}
+	c.Total = c.Subtotal.Sub(c.Discount)
+	if c.Total.Amount < 0 {
+		c.Total.Amount = 0
+	}
+	c.Total = c.Total.Add(exclusive)
+	if c.Shipping != nil {
+		c.Total = c.Total.Add(c.Shipping.Cost)
+	}
+}
+
+// TaxableAmounts devuelve la base imponible de cada item: su subtotal menos la
+// parte del descuento que le toca en proporción. El último item se lleva el
+// resto del redondeo.
+func (c *Cart) TaxableAmounts() []Money {
+	amounts := make([]Money, len(c.Items))
+	discount := c.Discount.Min(c.Subtotal)
+	remaining := discount
+	for i, item := range c.Items {
+		share := remaining
+		if i < len(c.Items)-1 && discount.Amount > 0 {
+			share = discount.Scale(item.Subtotal.Amount, c.Subtotal.Amount).Min(remaining)
+		}
+		share = share.Min(item.Subtotal)
+		remaining = remaining.Sub(share)
+		amounts[i] = item.Subtotal.Sub(share)
+	}
+	return amounts
+}
+
+// ApplyTaxes fija el impuesto de cada item, en el mismo orden que Items, y
+// recalcula el total. Un nil deja al item sin impuesto.
+func (c *Cart) ApplyTaxes(region string, lines []*TaxLine) {
+	c.TaxRegion = region
+	for i, item := range c.Items {
+		item.Tax = nil
+		if i < len(lines) {
+			item.Tax = lines[i]
+		}
+	}
+	c.CalculateTotals()
+}
+
+// ApplyDiscount fija el descuento del cupón y recalcula el total.
+func (c *Cart) ApplyDiscount(discount Money, freeShipping bool) {
+	c.Discount = discount
+	c.FreeShipping = freeShipping
+	c.CalculateTotals()
+}
+
+// ApplyShipping fija el envío elegido y recalcula el total.
+func (c *Cart) ApplyShipping(quote *ShippingQuote) {
+	c.Shipping = quote
+	c.CalculateTotals()
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCartTaxableAmounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		prices   []int64
+		discount int64
+		want     []int64
+	}{
+		{"no discount", []int64{1000, 500}, 0, []int64{1000, 500}},
+		{"proportional", []int64{3000, 1000}, 400, []int64{2700, 900}},
+		{"last item takes the rounding", []int64{100, 100, 100}, 100, []int64{67, 67, 66}},
+		{"discount above subtotal", []int64{300, 200}, 1000, []int64{0, 0}},
+		{"single item", []int64{999}, 1, []int64{998}},
+		{"empty cart", nil, 100, []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := &Cart{Discount: usd(tt.discount)}
+			for _, price := range tt.prices {
+				cart.Items = append(cart.Items, &CartItem{Price: usd(price), Quantity: 1})
+			}
+			cart.CalculateTotals()
+
+			got := []int64{}
+			total := int64(0)
+			for _, amount := range cart.TaxableAmounts() {
+				got = append(got, amount.Amount)
+				total += amount.Amount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TaxableAmounts = %v, want %v", got, tt.want)
+			}
+			if want := max(cart.Subtotal.Amount-tt.discount, 0); total != want {
+				t.Errorf("taxable total = %d, want %d", total, want)
+			}
+		})
+	}
+}
+
+func TestCartCalculateTotals(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount int64
+		taxes    []*TaxLine
+		shipping *ShippingQuote
+		wantTax  int64
+		want     int64
+	}{
+		{"plain", 0, nil, nil, 0, 2500},
+		{"discount", 500, nil, nil, 0, 2000},
+		{"discount never goes negative", 9000, nil, nil, 0, 0},
+		{"exclusive tax is added", 0, []*TaxLine{{Amount: usd(200)}, {Amount: usd(50)}}, nil, 250, 2750},
+		{"inclusive tax is not added", 0, []*TaxLine{{Amount: usd(174), Inclusive: true}, nil}, nil, 174, 2500},
+		{"shipping", 500, nil, &ShippingQuote{Cost: usd(700)}, 0, 2700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := &Cart{Items: []*CartItem{
+				{Price: usd(1000), Quantity: 2, WeightGrams: 300},
+				{Price: usd(500), Quantity: 1, WeightGrams: 150},
+			}}
+			cart.ApplyDiscount(usd(tt.discount), false)
+			cart.ApplyTaxes("US-CA", tt.taxes)
+			cart.ApplyShipping(tt.shipping)
+
+			if cart.TotalItems != 3 || cart.WeightGrams != 750 || cart.Subtotal != usd(2500) {
+				t.Errorf("items %d, weight %d, subtotal %s", cart.TotalItems, cart.WeightGrams, cart.Subtotal)
+			}
+			if cart.Tax != usd(tt.wantTax) {
+				t.Errorf("Tax = %s, want %d", cart.Tax, tt.wantTax)
+			}
+			if cart.Total != usd(tt.want) {
+				t.Errorf("Total = %s, want %d", cart.Total, tt.want)
+			}
+		})
+	}
+}
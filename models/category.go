@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+	ErrCategoryHasTaxRules = errors.New("category has tax rules; delete them first")
+	ErrCategoryCycle       = errors.New("a category cannot be nested under itself or its subcategories")
+)
+
+type Category struct {
+	Id         string      `json:"id"`
+	Name       string      `json:"name"`
+	ParentId   string      `json:"parent_id,omitempty"`
+	Created_at time.Time   `json:"created_at"`
+	Children   []*Category `json:"children,omitempty"`
+}
+
+// CategoryTree arma el árbol a partir de la lista plana; las categorías cuyo
+// padre no aparece en la lista quedan como raíces.
+func CategoryTree(categories []*Category) []*Category {
+	byId := make(map[string]*Category, len(categories))
+	for _, category := range categories {
+		category.Children = nil
+		byId[category.Id] = category
+	}
+
+	roots := []*Category{}
+	for _, category := range categories {
+		if parent, ok := byId[category.ParentId]; ok && category.ParentId != "" {
+			parent.Children = append(parent.Children, category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+	return roots
+}
+
+// DescendantIds devuelve el id de la categoría y los de todas sus
+// subcategorías.
+func DescendantIds(categories []*Category, id string) []string {
+	children := make(map[string][]string)
+	for _, category := range categories {
+		children[category.ParentId] = append(children[category.ParentId], category.Id)
+	}
+
+	ids := []string{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// AncestorIds devuelve el id de la categoría y los de todos sus padres hasta
+// la raíz.
+func AncestorIds(categories []*Category, id string) []string {
+	parents := make(map[string]string, len(categories))
+	for _, category := range categories {
+		parents[category.Id] = category.ParentId
+	}
+
+	ids := []string{id}
+	for parent := parents[id]; parent != "" && !slices.Contains(ids, parent); parent = parents[parent] {
+		ids = append(ids, parent)
+	}
+	return ids
+}
@@ -4,5 +4,21 @@ import "github.com/golang-jwt/jwt"
 
 type AppClaims struct {
 	UserId string `json:"userId"`
+	Role   Role   `json:"role,omitempty"`
 	jwt.StandardClaims
 }
+
+// HasRole trata como customer a los tokens emitidos antes de que existieran
+// los roles.
+func (c *AppClaims) HasRole(roles ...Role) bool {
+	role := c.Role
+	if role == "" {
+		role = RoleCustomer
+	}
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// countryCodes son los códigos ISO 3166-1 alfa-2 asignados.
+var countryCodes = func() map[string]bool {
+	codes := make(map[string]bool)
+	for _, code := range strings.Fields(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE
+	BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD
+	CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM
+	DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD GE GF
+	GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU
+	ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN
+	KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME
+	MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ NA
+	NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM
+	PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI
+	SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK
+	TL TM TN TO TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI
+	VN VU WF WS YE YT ZA ZM ZW
+`) {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// addressRule son los campos que exige un país además de los comunes. Los
+// países sin regla aceptan la dirección sin región ni código postal.
+type addressRule struct {
+	region     bool
+	postalCode *regexp.Regexp
+}
+
+var addressRules = map[string]addressRule{
+	"AR": {region: true, postalCode: regexp.MustCompile(`^([A-Z]\d{4}[A-Z]{3}|\d{4})$`)},
+	"AU": {region: true, postalCode: regexp.MustCompile(`^\d{4}$`)},
+	"BR": {region: true, postalCode: regexp.MustCompile(`^\d{5}-?\d{3}$`)},
+	"CA": {region: true, postalCode: regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`)},
+	"CL": {region: true, postalCode: regexp.MustCompile(`^\d{7}$`)},
+	"CO": {region: true},
+	"DE": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"ES": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"FR": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"GB": {postalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+	"IT": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"JP": {region: true, postalCode: regexp.MustCompile(`^\d{3}-?\d{4}$`)},
+	"MX": {region: true, postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"NL": {postalCode: regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`)},
+	"PT": {postalCode: regexp.MustCompile(`^\d{4}-\d{3}$`)},
+	"US": {region: true, postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+}
+
+func ValidCountry(code string) bool {
+	return countryCodes[code]
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type CouponType string
+
+const (
+	CouponPercentage   CouponType = "percentage"
+	CouponFixed        CouponType = "fixed"
+	CouponFreeShipping CouponType = "free_shipping"
+)
+
+var (
+	ErrCouponNotFound  = errors.New("coupon not found")
+	ErrDuplicateCoupon = errors.New("coupon code already exists")
+	ErrCouponInUse     = errors.New("coupon has redemptions; set ends_at to retire it instead")
+	// ErrInvalidCoupon envuelve todos los motivos por los que un cupón
+	// existente no se puede usar en un carrito.
+	ErrInvalidCoupon = errors.New("coupon cannot be applied")
+)
+
+// Coupon guarda los montos en DefaultCurrency. Percent es de 1 a 100 y Amount
+// solo aplica a los cupones de monto fijo. Cero en MaxUses o MaxUsesPerUser
+// significa sin límite. Si hay ProductIds o CategoryIds el descuento solo
+// aplica a esos productos.
+type Coupon struct {
+	Code           string     `json:"code"`
+	Type           CouponType `json:"type"`
+	Percent        int        `json:"percent,omitempty"`
+	Amount         Money      `json:"amount"`
+	MinSubtotal    Money      `json:"min_subtotal"`
+	Starts_at      *time.Time `json:"starts_at"`
+	Ends_at        *time.Time `json:"ends_at"`
+	MaxUses        int        `json:"max_uses"`
+	MaxUsesPerUser int        `json:"max_uses_per_user"`
+	ProductIds     []string   `json:"product_ids"`
+	CategoryIds    []string   `json:"category_ids"`
+	Uses           int        `json:"uses"`
+	Created_at     time.Time  `json:"created_at"`
+}
+
+type CouponRedemption struct {
+	Code       string    `json:"code"`
+	UserId     string    `json:"userId"`
+	OrderId    string    `json:"order_id"`
+	Discount   Money     `json:"discount"`
+	Created_at time.Time `json:"created_at"`
+}
+
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (c *Coupon) Validate() error {
+	if c.Code == "" || len(c.Code) > 32 {
+		return errors.New("code is required and must be at most 32 characters")
+	}
+	switch c.Type {
+	case CouponPercentage:
+		if c.Percent < 1 || c.Percent > 100 {
+			return errors.New("percent must be between 1 and 100")
+		}
+	case CouponFixed:
+		if c.Amount.Amount <= 0 {
+			return errors.New("amount must be positive")
+		}
+	case CouponFreeShipping:
+	default:
+		return errors.New("type must be percentage, fixed or free_shipping")
+	}
+	if c.MinSubtotal.Amount < 0 || c.MaxUses < 0 || c.MaxUsesPerUser < 0 {
+		return errors.New("limits must not be negative")
+	}
+	if c.Starts_at != nil && c.Ends_at != nil && !c.Starts_at.Before(*c.Ends_at) {
+		return errors.New("starts_at must be before ends_at")
+	}
+	return nil
+}
+
+// CheckAvailable revisa la vigencia y los límites de uso.
+func (c *Coupon) CheckAvailable(now time.Time, uses int, userUses int) error {
+	if c.Starts_at != nil && now.Before(*c.Starts_at) {
+		return fmt.Errorf("%w: coupon is not active yet", ErrInvalidCoupon)
+	}
+	if c.Ends_at != nil && !now.Before(*c.Ends_at) {
+		return fmt.Errorf("%w: coupon has expired", ErrInvalidCoupon)
+	}
+	if c.MaxUses > 0 && uses >= c.MaxUses {
+		return fmt.Errorf("%w: coupon usage limit reached", ErrInvalidCoupon)
+	}
+	if c.MaxUsesPerUser > 0 && userUses >= c.MaxUsesPerUser {
+		return fmt.Errorf("%w: you already used this coupon", ErrInvalidCoupon)
+	}
+	return nil
+}
+
+// Discount calcula el descuento sobre los items del carrito para los que
+// eligible devuelve true. El porcentaje se redondea a la unidad menor y el
+// monto fijo nunca supera lo elegible.
+func (c *Coupon) Discount(cart *Cart, eligible func(item *CartItem) bool) (Money, error) {
+	subtotal := NewMoney(0, DefaultCurrency)
+	eligibleSubtotal := NewMoney(0, DefaultCurrency)
+	for _, item := range cart.Items {
+		line := item.Price.Mul(int64(item.Quantity))
+		subtotal = subtotal.Add(line)
+		if eligible(item) {
+			eligibleSubtotal = eligibleSubtotal.Add(line)
+		}
+	}
+	if subtotal.Cmp(c.MinSubtotal) < 0 {
+		return Money{}, fmt.Errorf("%w: subtotal must be at least %s", ErrInvalidCoupon, c.MinSubtotal)
+	}
+	if eligibleSubtotal.Amount == 0 {
+		return Money{}, fmt.Errorf("%w: no items in the cart qualify for this coupon", ErrInvalidCoupon)
+	}
+
+	switch c.Type {
+	case CouponPercentage:
+		return eligibleSubtotal.Percent(c.Percent), nil
+	case CouponFixed:
+		return c.Amount.Min(eligibleSubtotal), nil
+	}
+	return NewMoney(0, DefaultCurrency), nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func usd(amount int64) Money {
+	return NewMoney(amount, DefaultCurrency)
+}
+
+func TestCouponDiscount(t *testing.T) {
+	cart := &Cart{Items: []*CartItem{
+		{ProductId: "a", Price: usd(1000), Quantity: 2},
+		{ProductId: "b", Price: usd(333), Quantity: 1},
+	}}
+	all := func(*CartItem) bool { return true }
+	onlyB := func(item *CartItem) bool { return item.ProductId == "b" }
+	none := func(*CartItem) bool { return false }
+
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		eligible func(*CartItem) bool
+		want     Money
+		wantErr  bool
+	}{
+		{"percentage of everything", Coupon{Type: CouponPercentage, Percent: 10}, all, usd(233), false},
+		{"percentage rounds half up", Coupon{Type: CouponPercentage, Percent: 15}, onlyB, usd(50), false},
+		{"full percentage", Coupon{Type: CouponPercentage, Percent: 100}, all, usd(2333), false},
+		{"fixed amount", Coupon{Type: CouponFixed, Amount: usd(500)}, all, usd(500), false},
+		{"fixed capped at eligible", Coupon{Type: CouponFixed, Amount: usd(500)}, onlyB, usd(333), false},
+		{"free shipping discounts nothing", Coupon{Type: CouponFreeShipping}, all, usd(0), false},
+		{"min subtotal reached", Coupon{Type: CouponFixed, Amount: usd(100), MinSubtotal: usd(2333)}, all, usd(100), false},
+		{"min subtotal counts all items", Coupon{Type: CouponFixed, Amount: usd(100), MinSubtotal: usd(2000)}, onlyB, usd(100), false},
+		{"below min subtotal", Coupon{Type: CouponFixed, Amount: usd(100), MinSubtotal: usd(2334)}, all, Money{}, true},
+		{"nothing eligible", Coupon{Type: CouponPercentage, Percent: 10}, none, Money{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.coupon.Discount(cart, tt.eligible)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCoupon) {
+					t.Fatalf("Discount error = %v, want ErrInvalidCoupon", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Discount error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Discount = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCouponCheckAvailable(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		uses     int
+		userUses int
+		wantErr  bool
+	}{
+		{"no limits", Coupon{}, 10, 10, false},
+		{"inside window", Coupon{Starts_at: &before, Ends_at: &after}, 0, 0, false},
+		{"not started", Coupon{Starts_at: &after}, 0, 0, true},
+		{"ends now", Coupon{Ends_at: &now}, 0, 0, true},
+		{"under max uses", Coupon{MaxUses: 2}, 1, 0, false},
+		{"max uses reached", Coupon{MaxUses: 2}, 2, 0, true},
+		{"per user reached", Coupon{MaxUsesPerUser: 1}, 0, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.coupon.CheckAvailable(now, tt.uses, tt.userUses)
+			if tt.wantErr != errors.Is(err, ErrInvalidCoupon) || (!tt.wantErr && err != nil) {
+				t.Errorf("CheckAvailable error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ProductCursor guarda la posición en el orden (columna de orden, id) del
+// último producto entregado. Viaja al cliente como un token opaco.
+// Currency es la moneda del precio cuando se ordena por precio.
+type ProductCursor struct {
+	SortBy   string `json:"s"`
+	SortDesc bool   `json:"d,omitempty"`
+	Value    string `json:"v"`
+	Currency string `json:"c,omitempty"`
+	Id       string `json:"i"`
+}
+
+type ProductPage struct {
+	Items      []*Products `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	HasMore    bool        `json:"has_more"`
+}
+
+func NewProductCursor(filter ProductFilter, last *Products) *ProductCursor {
+	cursor := &ProductCursor{SortBy: filter.SortBy, SortDesc: filter.SortDesc, Id: last.Id}
+	switch filter.SortBy {
+	case SortByPrice:
+		cursor.Value = last.Price.String()
+		cursor.Currency = last.Price.Currency
+	case SortByTitle:
+		cursor.Value = last.Title
+	default:
+		cursor.Value = last.Created_at.UTC().Format(time.RFC3339Nano)
+	}
+	return cursor
+}
+
+func (c *ProductCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeProductCursor(token string) (*ProductCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor ProductCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := cursor.typedValue(); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Compare indica si el producto va antes (-1), en (0) o después (1) de la
+// posición del cursor, según el orden del cursor.
+func (c *ProductCursor) Compare(product *Products) int {
+	value, _ := c.typedValue()
+	var order int
+	switch v := value.(type) {
+	case Money:
+		order = product.Price.Rat().Cmp(v.Rat())
+	case time.Time:
+		order = product.Created_at.Compare(v)
+	case string:
+		order = cmp.Compare(product.Title, v)
+	}
+	if order == 0 {
+		order = cmp.Compare(product.Id, c.Id)
+	}
+	if c.SortDesc {
+		return -order
+	}
+	return order
+}
+
+// SQLValue devuelve el valor con el tipo de la columna de orden.
+func (c *ProductCursor) SQLValue() any {
+	value, _ := c.typedValue()
+	return value
+}
+
+func (c *ProductCursor) typedValue() (any, error) {
+	switch c.SortBy {
+	case SortByPrice:
+		if c.Currency != "" && !ValidCurrency(c.Currency) {
+			return nil, ErrInvalidCursor
+		}
+		return ParseMoney(c.Value, c.Currency)
+	case SortByTitle:
+		return c.Value, nil
+	case SortByCreatedAt:
+		return time.Parse(time.RFC3339Nano, c.Value)
+	}
+	return nil, ErrInvalidCursor
+}
+
+// SearchCursor es la posición (rank, id) del último resultado de una
+// búsqueda. Los resultados van por rank descendente y luego por id.
+type SearchCursor struct {
+	Rank float64 `json:"r"`
+	Id   string  `json:"i"`
+}
+
+type ProductSearchPage struct {
+	Items      []*ProductSearchResult `json:"items"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	HasMore    bool                   `json:"has_more"`
+}
+
+func (c *SearchCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeSearchCursor(token string) (*SearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor SearchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Compare indica si el resultado va antes (-1), en (0) o después (1) del
+// cursor.
+func (c *SearchCursor) Compare(result *ProductSearchResult) int {
+	if order := cmp.Compare(c.Rank, result.Rank); order != 0 {
+		return order
+	}
+	return cmp.Compare(result.Id, c.Id)
+}
+
+// NewProductSearchPage recibe hasta Limit+1 resultados; el sobrante solo
+// indica que hay otra página.
+func NewProductSearchPage(search ProductSearch, results []*ProductSearchResult) *ProductSearchPage {
+	page := &ProductSearchPage{Items: results}
+	if len(results) > search.Limit {
+		page.Items = results[:search.Limit]
+		page.HasMore = true
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = (&SearchCursor{Rank: last.Rank, Id: last.Id}).Encode()
+	}
+	return page
+}
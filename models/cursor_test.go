@@ -0,0 +1,151 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	product := &Products{
+		Id:         "p1",
+		Title:      "Lamp",
+		Price:      NewMoney(1234, "KWD"),
+		Currency:   "KWD",
+		Created_at: created,
+	}
+	tests := []struct {
+		name   string
+		filter ProductFilter
+		value  any
+	}{
+		{"created_at", ProductFilter{SortBy: SortByCreatedAt}, created},
+		{"created_at desc", ProductFilter{SortBy: SortByCreatedAt, SortDesc: true}, created},
+		{"title", ProductFilter{SortBy: SortByTitle}, "Lamp"},
+		{"price keeps currency decimals", ProductFilter{SortBy: SortByPrice, SortDesc: true}, NewMoney(1234, "KWD")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := NewProductCursor(tt.filter, product)
+			decoded, err := DecodeProductCursor(cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeProductCursor error = %v", err)
+			}
+			if *decoded != *cursor {
+				t.Errorf("decoded = %+v, want %+v", decoded, cursor)
+			}
+			if got := decoded.SQLValue(); got != tt.value {
+				t.Errorf("SQLValue = %v, want %v", got, tt.value)
+			}
+			if got := decoded.Compare(product); got != 0 {
+				t.Errorf("Compare(last) = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestDecodeProductCursorInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "***"},
+		{"not json", encode("nope")},
+		{"missing id", encode(`{"s":"title","v":"Lamp"}`)},
+		{"unknown sort", encode(`{"s":"rating","v":"5","i":"p1"}`)},
+		{"bad time", encode(`{"s":"created_at","v":"yesterday","i":"p1"}`)},
+		{"bad price", encode(`{"s":"price","v":"cheap","i":"p1"}`)},
+		{"bad currency", encode(`{"s":"price","v":"1.00","c":"dollars","i":"p1"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeProductCursor(tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeProductCursor error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestProductCursorCompare(t *testing.T) {
+	cursor := &ProductCursor{SortBy: SortByPrice, Value: "10.00", Currency: "USD", Id: "m"}
+	tests := []struct {
+		name    string
+		product *Products
+		desc    bool
+		want    int
+	}{
+		{"cheaper", &Products{Id: "z", Price: NewMoney(999, "USD")}, false, -1},
+		{"pricier", &Products{Id: "a", Price: NewMoney(1001, "USD")}, false, 1},
+		{"same price smaller id", &Products{Id: "a", Price: NewMoney(1000, "USD")}, false, -1},
+		{"same price bigger id", &Products{Id: "z", Price: NewMoney(1000, "USD")}, false, 1},
+		{"other currency same value", &Products{Id: "m", Price: NewMoney(10, "JPY")}, false, 0},
+		{"cheaper descending", &Products{Id: "z", Price: NewMoney(999, "USD")}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cursor
+			c.SortDesc = tt.desc
+			if got := c.Compare(tt.product); got != tt.want {
+				t.Errorf("Compare = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchCursor(t *testing.T) {
+	cursor := &SearchCursor{Rank: 0.5, Id: "m"}
+	decoded, err := DecodeSearchCursor(cursor.Encode())
+	if err != nil || *decoded != *cursor {
+		t.Fatalf("DecodeSearchCursor = %+v, %v, want %+v", decoded, err, cursor)
+	}
+	if _, err := DecodeSearchCursor(base64.RawURLEncoding.EncodeToString([]byte(`{"r":1}`))); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("DecodeSearchCursor without id error = %v, want ErrInvalidCursor", err)
+	}
+
+	tests := []struct {
+		name string
+		rank float64
+		id   string
+		want int
+	}{
+		{"higher rank goes before", 0.9, "z", -1},
+		{"lower rank goes after", 0.1, "a", 1},
+		{"same rank smaller id", 0.5, "a", -1},
+		{"same rank bigger id", 0.5, "z", 1},
+		{"same position", 0.5, "m", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ProductSearchResult{Products: Products{Id: tt.id}, Rank: tt.rank}
+			if got := cursor.Compare(result); got != tt.want {
+				t.Errorf("Compare = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProductSearchPage(t *testing.T) {
+	results := []*ProductSearchResult{
+		{Products: Products{Id: "a"}, Rank: 0.9},
+		{Products: Products{Id: "b"}, Rank: 0.5},
+		{Products: Products{Id: "c"}, Rank: 0.1},
+	}
+	page := NewProductSearchPage(ProductSearch{Limit: 2}, results)
+	if len(page.Items) != 2 || !page.HasMore {
+		t.Fatalf("page = %d items, has_more %v; want 2, true", len(page.Items), page.HasMore)
+	}
+	cursor, err := DecodeSearchCursor(page.NextCursor)
+	if err != nil || cursor.Id != "b" || cursor.Rank != 0.5 {
+		t.Errorf("next cursor = %+v, %v; want b at 0.5", cursor, err)
+	}
+
+	page = NewProductSearchPage(ProductSearch{Limit: 3}, results)
+	if len(page.Items) != 3 || page.HasMore || page.NextCursor != "" {
+		t.Errorf("last page = %d items, has_more %v, cursor %q", len(page.Items), page.HasMore, page.NextCursor)
+	}
+}
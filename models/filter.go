@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+const (
+	SortByCreatedAt = "created_at"
+	SortByPrice     = "price"
+	SortByTitle     = "title"
+)
+
+const (
+	DefaultProductLimit = 20
+	MaxProductLimit     = 100
+)
+
+// ProductFilter describe el listado de productos. Los campos vacíos no
+// filtran; los precios son punteros porque 0 es un límite válido. After es
+// la posición del último producto de la página anterior. CategoryId incluye
+// también las subcategorías.
+//
+// Currency es la moneda de MinPrice, MaxPrice y del orden por precio. Montos
+// de monedas distintas no se comparan, así que al filtrar u ordenar por
+// precio solo entran los productos con precio en Currency.
+type ProductFilter struct {
+	Limit         int
+	After         *ProductCursor
+	MinPrice      *big.Rat
+	MaxPrice      *big.Rat
+	Currency      string
+	UserId        string
+	CategoryId    string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	SortBy        string
+	SortDesc      bool
+}
+
+func (f *ProductFilter) Validate() error {
+	switch f.SortBy {
+	case "":
+		f.SortBy = SortByCreatedAt
+	case SortByCreatedAt, SortByPrice, SortByTitle:
+	default:
+		return errors.New("sort must be one of created_at, price, title")
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultProductLimit
+	}
+	if f.Limit > MaxProductLimit {
+		f.Limit = MaxProductLimit
+	}
+	if f.After != nil && (f.After.SortBy != f.SortBy || f.After.SortDesc != f.SortDesc) {
+		return errors.New("cursor does not match the requested sort")
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && f.MinPrice.Cmp(f.MaxPrice) > 0 {
+		return errors.New("min_price must not be greater than max_price")
+	}
+	if f.ComparesPrices() {
+		f.Currency = NormalizeCurrency(f.Currency)
+		if f.After != nil && f.SortBy == SortByPrice && NormalizeCurrency(f.After.Currency) != f.Currency {
+			return errors.New("cursor does not match the requested currency")
+		}
+	} else {
+		f.Currency = ""
+	}
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && f.CreatedAfter.After(f.CreatedBefore) {
+		return errors.New("created_after must be before created_before")
+	}
+	return nil
+}
+
+// ComparesPrices dice si el filtro o el orden usan el precio.
+func (f *ProductFilter) ComparesPrices() bool {
+	return f.MinPrice != nil || f.MaxPrice != nil || f.SortBy == SortByPrice
+}
+
+// Matches aplica el filtro a un producto ya cargado; lo usan los repositorios
+// que no pueden filtrar en la consulta.
+func (f *ProductFilter) Matches(product *Products) bool {
+	if f.Currency != "" && NormalizeCurrency(product.Price.Currency) != f.Currency {
+		return false
+	}
+	if f.MinPrice != nil && product.Price.Rat().Cmp(f.MinPrice) < 0 {
+		return false
+	}
+	if f.MaxPrice != nil && product.Price.Rat().Cmp(f.MaxPrice) > 0 {
+		return false
+	}
+	if f.UserId != "" && product.UserId != f.UserId {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !product.Created_at.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !product.Created_at.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// ProductSearch es una búsqueda de texto paginada igual que el listado.
+type ProductSearch struct {
+	Query string
+	Limit int
+	After *SearchCursor
+}
+
+func (s *ProductSearch) Validate() error {
+	if s.Query == "" {
+		return errors.New("q is required")
+	}
+	if s.Limit <= 0 {
+		s.Limit = DefaultProductLimit
+	}
+	if s.Limit > MaxProductLimit {
+		s.Limit = MaxProductLimit
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	MaxImageSize  = 5 << 20
+	ThumbnailSize = 320
+)
+
+// ImageExtensions son los tipos de imagen aceptados y la extensión con la que
+// se guardan.
+var ImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type ProductImage struct {
+	Id           string    `json:"id"`
+	ProductId    string    `json:"product_id"`
+	Position     int       `json:"position"`
+	Url          string    `json:"url"`
+	ThumbnailUrl string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	Created_at   time.Time `json:"created_at"`
+}
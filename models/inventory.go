@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ReservationTTL es cuánto se guarda el stock de una orden pendiente antes de
+// cancelarla por falta de pago.
+const ReservationTTL = 30 * time.Minute
+
+var ErrOutOfStock = errors.New("not enough stock")
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+)
+
+// Inventory es el stock de un producto. Stock son las unidades disponibles y
+// Reserved las apartadas por órdenes pendientes de pago. Un producto sin
+// inventario no lleva control de stock.
+type Inventory struct {
+	ProductId  string          `json:"product_id"`
+	Tracked    bool            `json:"tracked"`
+	Stock      int             `json:"stock"`
+	Reserved   int             `json:"reserved"`
+	Variants   []*VariantStock `json:"variants"`
+	Updated_at time.Time       `json:"updated_at"`
+}
+
+type VariantStock struct {
+	Id    string `json:"id"`
+	Sku   string `json:"sku"`
+	Stock int    `json:"stock"`
+}
+
+// Reservation aparta stock del producto o, si VariantId no está vacío, de la
+// variante.
+type Reservation struct {
+	OrderId    string            `json:"order_id"`
+	ProductId  string            `json:"product_id"`
+	VariantId  string            `json:"variant_id,omitempty"`
+	Quantity   int               `json:"quantity"`
+	Status     ReservationStatus `json:"status"`
+	Expires_at time.Time         `json:"expires_at"`
+	Created_at time.Time         `json:"created_at"`
+}
@@ -0,0 +1,287 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "USD"
+
+// MaxCurrencyExponent es la mayor cantidad de decimales de una moneda; las
+// columnas de precios guardan esa precisión.
+const MaxCurrencyExponent = 3
+
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// currencyExponents guarda los decimales de las monedas ISO 4217 que no usan
+// dos; el resto usa dos.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Money es un monto exacto en unidades menores de la moneda (centavos para
+// USD, yenes para JPY). Una moneda vacía se trata como DefaultCurrency.
+//
+// En JSON se escribe como número decimal ("price": 19.99) para que los
+// clientes que leían float64 sigan funcionando; en SQL como texto decimal para
+// columnas NUMERIC. La moneda no viaja en ninguno de los dos: la guarda cada
+// modelo aparte.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: NormalizeCurrency(currency)}
+}
+
+func NormalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+// ValidCurrency solo revisa la forma del código: tres letras.
+func ValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[NormalizeCurrency(currency)]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// ParseMoney lee un decimal como "19.99" sin pasar por float64. Los
+// decimales de más se redondean a la mitad alejándose de cero.
+func ParseMoney(value string, currency string) (Money, error) {
+	currency = NormalizeCurrency(currency)
+	exponent := CurrencyExponent(currency)
+
+	s := strings.TrimSpace(value)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, value)
+	}
+	whole = strings.TrimLeft(whole, "0")
+	if len(whole)+exponent > 18 {
+		return Money{}, fmt.Errorf("%w: %q is too large", ErrInvalidMoney, value)
+	}
+
+	roundUp := len(fraction) > exponent && fraction[exponent] >= '5'
+	if len(fraction) > exponent {
+		fraction = fraction[:exponent]
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	var amount int64
+	for _, c := range whole + fraction {
+		amount = amount*10 + int64(c-'0')
+	}
+	if roundUp {
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) currency() string {
+	return NormalizeCurrency(m.Currency)
+}
+
+// String devuelve el monto con los decimales de su moneda, sin el código.
+func (m Money) String() string {
+	exponent := CurrencyExponent(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Las operaciones entre montos exigen la misma moneda; mezclar monedas es un
+// error de programación porque hay que convertir antes.
+func (m Money) mustMatch(other Money) {
+	if m.currency() != other.currency() {
+		panic(fmt.Sprintf("money: mixing %s and %s", m.currency(), other.currency()))
+	}
+}
+
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currency()}
+}
+
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currency()}
+}
+
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.currency()}
+}
+
+// Scale multiplica por numerator/denominator redondeando a la unidad menor,
+// con la mitad alejándose de cero.
+func (m Money) Scale(numerator int64, denominator int64) Money {
+	return Money{Amount: divRound(m.Amount*numerator, denominator), Currency: m.currency()}
+}
+
+func (m Money) Percent(percent int) Money {
+	return m.Scale(int64(percent), 100)
+}
+
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	}
+	return 0
+}
+
+// Rat es el valor decimal sin moneda, el mismo que guarda la columna NUMERIC.
+// Sirve para filtrar y ordenar el catálogo por precio aunque los productos
+// tengan monedas distintas; para sumar o cobrar hay que convertir antes.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(CurrencyExponent(m.Currency)))
+}
+
+func (m Money) Min(other Money) Money {
+	if m.Cmp(other) <= 0 {
+		return Money{Amount: m.Amount, Currency: m.currency()}
+	}
+	return Money{Amount: other.Amount, Currency: m.currency()}
+}
+
+// Convert pasa el monto a otra moneda. rate es cuántas unidades de la moneda
+// destino vale una unidad de la moneda origen; el resultado se redondea a los
+// decimales de la moneda destino, con la mitad alejándose de cero.
+func (m Money) Convert(currency string, rate *big.Rat) Money {
+	currency = NormalizeCurrency(currency)
+	value := new(big.Rat).SetInt64(m.Amount)
+	value.Mul(value, rate)
+	value.Mul(value, new(big.Rat).SetFrac(pow10(CurrencyExponent(currency)), pow10(CurrencyExponent(m.Currency))))
+
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	remainder.Abs(remainder).Lsh(remainder, 1)
+	if remainder.Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	return Money{Amount: quotient.Int64(), Currency: currency}
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+func divRound(value int64, divisor int64) int64 {
+	if divisor < 0 {
+		value, divisor = -value, -divisor
+	}
+	quotient, remainder := value/divisor, value%divisor
+	if remainder < 0 {
+		remainder = -remainder
+	}
+	if 2*remainder >= divisor {
+		if value < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return quotient
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON acepta un número o un texto decimal. La moneda no cambia.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	parsed, err := ParseMoney(value, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan lee columnas NUMERIC. La moneda tiene que estar puesta antes si no es
+// DefaultCurrency.
+func (m *Money) Scan(src any) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		m.Amount = 0
+		return nil
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	case int64:
+		value = strconv.FormatInt(v, 10)
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, src)
+	}
+	parsed, err := ParseMoney(value, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		{value: "19.99", currency: "USD", want: Money{Amount: 1999, Currency: "USD"}},
+		{value: "19.9", currency: "usd", want: Money{Amount: 1990, Currency: "USD"}},
+		{value: "19", currency: "", want: Money{Amount: 1900, Currency: "USD"}},
+		{value: ".5", currency: "USD", want: Money{Amount: 50, Currency: "USD"}},
+		{value: "+3", currency: "USD", want: Money{Amount: 300, Currency: "USD"}},
+		{value: "-1.25", currency: "USD", want: Money{Amount: -125, Currency: "USD"}},
+		{value: " 7.10 ", currency: "USD", want: Money{Amount: 710, Currency: "USD"}},
+		{value: "0.005", currency: "USD", want: Money{Amount: 1, Currency: "USD"}},
+		{value: "0.004", currency: "USD", want: Money{Amount: 0, Currency: "USD"}},
+		{value: "-0.005", currency: "USD", want: Money{Amount: -1, Currency: "USD"}},
+		{value: "1500", currency: "JPY", want: Money{Amount: 1500, Currency: "JPY"}},
+		{value: "1499.5", currency: "JPY", want: Money{Amount: 1500, Currency: "JPY"}},
+		{value: "1.234", currency: "KWD", want: Money{Amount: 1234, Currency: "KWD"}},
+		{value: "1.2345", currency: "KWD", want: Money{Amount: 1235, Currency: "KWD"}},
+		{value: "", currency: "USD", wantErr: true},
+		{value: ".", currency: "USD", wantErr: true},
+		{value: "abc", currency: "USD", wantErr: true},
+		{value: "1.2.3", currency: "USD", wantErr: true},
+		{value: "1e3", currency: "USD", wantErr: true},
+		{value: "1000000000000000000", currency: "USD", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.currency, func(t *testing.T) {
+			got, err := ParseMoney(tt.value, tt.currency)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Fatalf("ParseMoney(%q) error = %v, want ErrInvalidMoney", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{NewMoney(1999, "USD"), "19.99"},
+		{NewMoney(5, "USD"), "0.05"},
+		{NewMoney(0, ""), "0.00"},
+		{NewMoney(-125, "USD"), "-1.25"},
+		{NewMoney(1500, "JPY"), "1500"},
+		{NewMoney(1234, "KWD"), "1.234"},
+		{NewMoney(7, "KWD"), "0.007"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScale(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      int64
+		numerator   int64
+		denominator int64
+		want        int64
+	}{
+		{"exact", 1000, 21, 100, 210},
+		{"half rounds up", 250, 1, 100, 3},
+		{"below half rounds down", 249, 1, 100, 2},
+		{"negative half rounds away from zero", -250, 1, 100, -3},
+		{"negative denominator", 250, 1, -100, -3},
+		{"inclusive tax", 1210, 2100, 12100, 210},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMoney(tt.amount, "USD").Scale(tt.numerator, tt.denominator)
+			if got.Amount != tt.want {
+				t.Errorf("Scale(%d, %d) of %d = %d, want %d", tt.numerator, tt.denominator, tt.amount, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		to    string
+		rate  string
+		want  Money
+	}{
+		{"usd to eur", NewMoney(1000, "USD"), "EUR", "0.9", NewMoney(900, "EUR")},
+		{"usd to jpy rounds to whole yen", NewMoney(1999, "USD"), "JPY", "149.5", NewMoney(2989, "JPY")},
+		{"jpy to usd", NewMoney(1500, "JPY"), "USD", "0.0066667", NewMoney(1000, "USD")},
+		{"usd to kwd keeps three decimals", NewMoney(1000, "USD"), "KWD", "0.307", NewMoney(3070, "KWD")},
+		{"kwd to usd rounds half up", NewMoney(1234, "KWD"), "USD", "3.2573", NewMoney(402, "USD")},
+		{"half cent rounds away from zero", NewMoney(1, "USD"), "EUR", "0.5", NewMoney(1, "EUR")},
+		{"negative half cent", NewMoney(-1, "USD"), "EUR", "0.5", NewMoney(-1, "EUR")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := new(big.Rat).SetString(tt.rate)
+			if !ok {
+				t.Fatalf("bad rate %q", tt.rate)
+			}
+			if got := tt.money.Convert(tt.to, rate); got != tt.want {
+				t.Errorf("Convert = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyUnmarshalJSONKeepsCurrency(t *testing.T) {
+	tests := []struct {
+		data     string
+		currency string
+		want     int64
+	}{
+		{`19.99`, "USD", 1999},
+		{`"19.99"`, "USD", 1999},
+		{`1500`, "JPY", 1500},
+		{`"1.234"`, "KWD", 1234},
+	}
+	for _, tt := range tests {
+		money := NewMoney(0, tt.currency)
+		if err := money.UnmarshalJSON([]byte(tt.data)); err != nil {
+			t.Fatalf("UnmarshalJSON(%s) error = %v", tt.data, err)
+		}
+		if money.Amount != tt.want || money.Currency != tt.currency {
+			t.Errorf("UnmarshalJSON(%s) in %s = %+v, want %d", tt.data, tt.currency, money, tt.want)
+		}
+	}
+}
+
+func TestMoneyScanUsesCurrencyExponent(t *testing.T) {
+	tests := []struct {
+		src      any
+		currency string
+		want     int64
+	}{
+		{[]byte("10.50"), "USD", 1050},
+		{"1500.000", "JPY", 1500},
+		{[]byte("1.234"), "KWD", 1234},
+		{int64(3), "USD", 300},
+		{nil, "USD", 0},
+	}
+	for _, tt := range tests {
+		money := NewMoney(99, tt.currency)
+		if err := money.Scan(tt.src); err != nil {
+			t.Fatalf("Scan(%v) error = %v", tt.src, err)
+		}
+		if money.Amount != tt.want || money.Currency != tt.currency {
+			t.Errorf("Scan(%v) in %s = %+v, want %d", tt.src, tt.currency, money, tt.want)
+		}
+	}
+}
+
+func TestMoneyRat(t *testing.T) {
+	tests := []struct {
+		a, b Money
+		want int
+	}{
+		{NewMoney(1000, "USD"), NewMoney(10, "JPY"), 0},
+		{NewMoney(1234, "KWD"), NewMoney(123, "USD"), 1},
+		{NewMoney(1, "JPY"), NewMoney(101, "USD"), -1},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Rat().Cmp(tt.b.Rat()); got != tt.want {
+			t.Errorf("%s %s vs %s %s = %d, want %d", tt.a, tt.a.Currency, tt.b, tt.b.Currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMixedCurrenciesPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Add with different currencies did not panic")
+		}
+	}()
+	NewMoney(1, "USD").Add(NewMoney(1, "EUR"))
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaid      OrderStatus = "paid"
+	OrderShipped   OrderStatus = "shipped"
+	OrderDelivered OrderStatus = "delivered"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+var (
+	ErrEmptyCart              = errors.New("cart is empty")
+	ErrInvalidOrderTransition = errors.New("invalid order status transition")
+)
+
+// orderTransitions define la máquina de estados de una orden: pending → paid →
+// shipped → delivered, y la cancelación mientras no se haya enviado.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderShipped, OrderCancelled},
+	OrderShipped: {OrderDelivered},
+}
+
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case OrderPending, OrderPaid, OrderShipped, OrderDelivered, OrderCancelled:
+		return true
+	}
+	return false
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type OrderItem struct {
+	ProductId string   `json:"product_id"`
+	VariantId string   `json:"variant_id,omitempty"`
+	Sku       string   `json:"sku,omitempty"`
+	Title     string   `json:"title"`
+	Price     Money    `json:"price"`
+	Quantity  int      `json:"quantity"`
+	Subtotal  Money    `json:"subtotal"`
+	Tax       *TaxLine `json:"tax,omitempty"`
+}
+
+type Order struct {
+	Id              string         `json:"id"`
+	UserId          string         `json:"userId"`
+	Status          OrderStatus    `json:"status"`
+	Items           []*OrderItem   `json:"items"`
+	CouponCode      string         `json:"coupon_code,omitempty"`
+	Discount        Money          `json:"discount"`
+	FreeShipping    bool           `json:"free_shipping"`
+	TaxRegion       string         `json:"tax_region,omitempty"`
+	Tax             Money          `json:"tax"`
+	Shipping        *ShippingQuote `json:"shipping,omitempty"`
+	ShippingAddress *Address       `json:"shipping_address,omitempty"`
+	Total           Money          `json:"total"`
+	Created_at      time.Time      `json:"created_at"`
+	Updated_at      time.Time      `json:"updated_at"`
+}
+
+// NewOrderFromCart copia título y precio de cada producto del carrito para que
+// la orden no cambie si después se edita el catálogo. El descuento del cupón,
+// los impuestos y el envío ya tienen que estar aplicados en el carrito.
+func NewOrderFromCart(id string, cart *Cart) (*Order, error) {
+	if len(cart.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	cart.CalculateTotals()
+
+	order := &Order{
+		Id:           id,
+		UserId:       cart.UserId,
+		Status:       OrderPending,
+		CouponCode:   cart.CouponCode,
+		Discount:     cart.Discount,
+		FreeShipping: cart.FreeShipping,
+		TaxRegion:    cart.TaxRegion,
+		Tax:          cart.Tax,
+		Shipping:     cart.Shipping,
+		Total:        cart.Total,
+	}
+	for _, item := range cart.Items {
+		order.Items = append(order.Items, &OrderItem{
+			ProductId: item.ProductId,
+			VariantId: item.VariantId,
+			Sku:       item.Sku,
+			Title:     item.Title,
+			Price:     item.Price,
+			Quantity:  item.Quantity,
+			Subtotal:  item.Subtotal,
+			Tax:       item.Tax,
+		})
+	}
+	return order, nil
+}
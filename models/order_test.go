@@ -0,0 +1,31 @@
+package models
+
+import "testing"
+
+func TestOrderStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{OrderPending, OrderPaid, true},
+		{OrderPending, OrderCancelled, true},
+		{OrderPending, OrderShipped, false},
+		{OrderPending, OrderDelivered, false},
+		{OrderPending, OrderPending, false},
+		{OrderPaid, OrderShipped, true},
+		{OrderPaid, OrderCancelled, true},
+		{OrderPaid, OrderPending, false},
+		{OrderShipped, OrderDelivered, true},
+		{OrderShipped, OrderCancelled, false},
+		{OrderDelivered, OrderCancelled, false},
+		{OrderDelivered, OrderPending, false},
+		{OrderCancelled, OrderPending, false},
+		{OrderCancelled, OrderPaid, false},
+		{OrderStatus("lost"), OrderPaid, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type Payment struct {
+	Id         string    `json:"id"`
+	OrderId    string    `json:"order_id"`
+	UserId     string    `json:"userId"`
+	Provider   string    `json:"provider"`
+	Reference  string    `json:"reference"`
+	Amount     Money     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Created_at time.Time `json:"created_at"`
+	Updated_at time.Time `json:"updated_at"`
+}
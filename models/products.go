@@ -1,13 +1,53 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrProductNotFound indica que el producto no existe o no es del dueño con el
+// que se filtró la escritura.
+var ErrProductNotFound = errors.New("product not found")
+
+// Dimensions son el peso en gramos y las medidas del paquete en milímetros;
+// cero significa que no se cargaron.
+type Dimensions struct {
+	WeightGrams int `json:"weight_grams,omitempty"`
+	LengthMm    int `json:"length_mm,omitempty"`
+	WidthMm     int `json:"width_mm,omitempty"`
+	HeightMm    int `json:"height_mm,omitempty"`
+}
+
+func (d Dimensions) Validate() error {
+	if d.WeightGrams < 0 || d.LengthMm < 0 || d.WidthMm < 0 || d.HeightMm < 0 {
+		return errors.New("weight and dimensions must not be negative")
+	}
+	return nil
+}
 
 type Products struct {
-	Id          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	ImageUrl    string    `json"image_url"`
-	Price       float64   `json:"price"`
-	Created_at  time.Time `json:"created_at"`
-	UserId      string    `json:"userId"`
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageUrl    string `json:"image_url"`
+	Price       Money  `json:"price"`
+	Currency    string `json:"currency"`
+	TaxClass    string `json:"tax_class,omitempty"`
+	Dimensions
+	Created_at time.Time `json:"created_at"`
+	Updated_at time.Time `json:"updated_at"`
+	UserId     string    `json:"userId"`
+}
+
+// CatalogVersion resume el catálogo para el feed: el último cambio de un
+// producto o de su stock, borrados incluidos, y cuántos productos hay.
+type CatalogVersion struct {
+	Updated_at time.Time
+	Products   int
+}
+
+type ProductSearchResult struct {
+	Products
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrDuplicateReview = errors.New("you already reviewed this product")
+
+type Review struct {
+	Id         string    `json:"id"`
+	ProductId  string    `json:"product_id"`
+	UserId     string    `json:"userId"`
+	Rating     int       `json:"rating"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	Created_at time.Time `json:"created_at"`
+}
+
+// ReviewSummary resume las reseñas de un producto; sin reseñas el promedio es
+// 0.
+type ReviewSummary struct {
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int     `json:"review_count"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type ShippingMethodType string
+
+const (
+	ShippingFlat     ShippingMethodType = "flat"
+	ShippingWeight   ShippingMethodType = "weight"
+	ShippingFreeOver ShippingMethodType = "free_over"
+)
+
+var (
+	ErrShippingMethodNotFound = errors.New("shipping method not found")
+	ErrShippingUnavailable    = errors.New("shipping method is not available for this cart and destination")
+)
+
+// ShippingMethod guarda los montos en DefaultCurrency. Las de tipo flat cobran
+// Price; las weight cobran Price más PerKg por cada kilo o fracción; las
+// free_over cobran Price salvo que el carrito, ya descontado el cupón, llegue a
+// FreeOver. Sin Regions el método sirve para cualquier destino y cero en
+// MaxWeightGrams significa sin límite de peso.
+type ShippingMethod struct {
+	Id             string             `json:"id"`
+	Name           string             `json:"name"`
+	Type           ShippingMethodType `json:"type"`
+	Regions        []string           `json:"regions"`
+	Price          Money              `json:"price"`
+	PerKg          Money              `json:"per_kg"`
+	FreeOver       Money              `json:"free_over"`
+	MaxWeightGrams int                `json:"max_weight_grams"`
+	Created_at     time.Time          `json:"created_at"`
+}
+
+// ShippingQuote es el costo de un método para un carrito; la orden guarda una
+// copia del elegido.
+type ShippingQuote struct {
+	MethodId string             `json:"method_id"`
+	Name     string             `json:"name"`
+	Type     ShippingMethodType `json:"type"`
+	Cost     Money              `json:"cost"`
+}
+
+func (m *ShippingMethod) Validate() error {
+	if m.Name == "" || len(m.Name) > 120 {
+		return errors.New("name is required and must be at most 120 characters")
+	}
+	switch m.Type {
+	case ShippingFlat:
+	case ShippingWeight:
+		if m.PerKg.Amount <= 0 {
+			return errors.New("per_kg must be positive")
+		}
+	case ShippingFreeOver:
+		if m.FreeOver.Amount <= 0 {
+			return errors.New("free_over must be positive")
+		}
+	default:
+		return errors.New("type must be flat, weight or free_over")
+	}
+	if m.Price.Amount < 0 || m.PerKg.Amount < 0 || m.FreeOver.Amount < 0 || m.MaxWeightGrams < 0 {
+		return errors.New("prices and limits must not be negative")
+	}
+	for _, region := range m.Regions {
+		if !ValidRegion(region) {
+			return errors.New("regions must be ISO country codes, optionally followed by a subdivision (US-CA)")
+		}
+	}
+	return nil
+}
+
+// Quote calcula el costo del envío del carrito al destino. Devuelve false si
+// el método no llega al destino o el carrito pesa más de lo permitido. Un
+// cupón de envío gratis deja el costo en cero.
+func (m *ShippingMethod) Quote(cart *Cart, destination string) (*ShippingQuote, bool) {
+	if len(m.Regions) > 0 && !regionListIncludes(m.Regions, destination) {
+		return nil, false
+	}
+	if m.MaxWeightGrams > 0 && cart.WeightGrams > m.MaxWeightGrams {
+		return nil, false
+	}
+
+	cost := m.Price
+	switch m.Type {
+	case ShippingWeight:
+		kilos := (cart.WeightGrams + 999) / 1000
+		cost = cost.Add(m.PerKg.Mul(int64(kilos)))
+	case ShippingFreeOver:
+		merchandise := cart.Subtotal.Sub(cart.Discount.Min(cart.Subtotal))
+		if merchandise.Cmp(m.FreeOver) >= 0 {
+			cost = NewMoney(0, DefaultCurrency)
+		}
+	}
+	if cart.FreeShipping {
+		cost = NewMoney(0, DefaultCurrency)
+	}
+	return &ShippingQuote{MethodId: m.Id, Name: m.Name, Type: m.Type, Cost: cost}, true
+}
+
+func regionListIncludes(regions []string, destination string) bool {
+	for _, region := range regions {
+		if RegionIncludes(region, destination) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package models
+
+import "testing"
+
+func TestShippingMethodQuote(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      ShippingMethod
+		weight      int
+		discount    int64
+		free        bool
+		destination string
+		want        int64
+		wantOk      bool
+	}{
+		{"flat", ShippingMethod{Type: ShippingFlat, Price: usd(500)}, 1200, 0, false, "US-CA", 500, true},
+		{"weight rounds up to the next kilo", ShippingMethod{Type: ShippingWeight, Price: usd(300), PerKg: usd(200)}, 1001, 0, false, "US-CA", 700, true},
+		{"weight exact kilo", ShippingMethod{Type: ShippingWeight, Price: usd(300), PerKg: usd(200)}, 2000, 0, false, "US-CA", 700, true},
+		{"weight empty cart", ShippingMethod{Type: ShippingWeight, Price: usd(300), PerKg: usd(200)}, 0, 0, false, "US-CA", 300, true},
+		{"free over reached", ShippingMethod{Type: ShippingFreeOver, Price: usd(800), FreeOver: usd(5000)}, 0, 0, false, "US-CA", 0, true},
+		{"free over missed after discount", ShippingMethod{Type: ShippingFreeOver, Price: usd(800), FreeOver: usd(5000)}, 0, 1, false, "US-CA", 800, true},
+		{"free shipping coupon", ShippingMethod{Type: ShippingFlat, Price: usd(500)}, 0, 0, true, "US-CA", 0, true},
+		{"country covers subdivision", ShippingMethod{Type: ShippingFlat, Price: usd(500), Regions: []string{"MX", "US"}}, 0, 0, false, "US-CA", 500, true},
+		{"subdivision only", ShippingMethod{Type: ShippingFlat, Price: usd(500), Regions: []string{"US-NY"}}, 0, 0, false, "US-CA", 0, false},
+		{"other country", ShippingMethod{Type: ShippingFlat, Price: usd(500), Regions: []string{"US"}}, 0, 0, false, "CA", 0, false},
+		{"within max weight", ShippingMethod{Type: ShippingFlat, Price: usd(500), MaxWeightGrams: 1200}, 1200, 0, false, "US", 500, true},
+		{"over max weight", ShippingMethod{Type: ShippingFlat, Price: usd(500), MaxWeightGrams: 1000}, 1200, 0, false, "US", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := &Cart{Items: []*CartItem{{Price: usd(5000), Quantity: 1, WeightGrams: tt.weight}}}
+			cart.ApplyDiscount(usd(tt.discount), tt.free)
+
+			tt.method.Id = "m1"
+			quote, ok := tt.method.Quote(cart, tt.destination)
+			if ok != tt.wantOk {
+				t.Fatalf("Quote ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if quote.Cost != usd(tt.want) || quote.MethodId != "m1" || quote.Type != tt.method.Type {
+				t.Errorf("Quote = %+v, want cost %d", quote, tt.want)
+			}
+		})
+	}
+}
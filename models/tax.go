@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrTaxRuleNotFound = errors.New("tax rule not found")
+
+var (
+	regionPattern   = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+	taxClassPattern = regexp.MustCompile(`^[a-z0-9_-]{0,32}$`)
+)
+
+// TaxRule aplica RateBps (puntos básicos, 2100 = 21%) a los productos de una
+// región. Region es un país ISO ("ES") o una subdivisión ("US-CA"); una regla
+// de país también vale para sus subdivisiones. La regla puede limitarse a una
+// categoría o a una clase de impuesto, pero no a las dos. Si es Inclusive el
+// impuesto ya viene dentro del precio.
+type TaxRule struct {
+	Id         string    `json:"id"`
+	Region     string    `json:"region"`
+	CategoryId string    `json:"category_id,omitempty"`
+	TaxClass   string    `json:"tax_class,omitempty"`
+	RateBps    int       `json:"rate_bps"`
+	Inclusive  bool      `json:"inclusive"`
+	Created_at time.Time `json:"created_at"`
+}
+
+// TaxLine es el impuesto de un item del carrito o de la orden.
+type TaxLine struct {
+	RuleId    string `json:"rule_id"`
+	RateBps   int    `json:"rate_bps"`
+	Inclusive bool   `json:"inclusive"`
+	Amount    Money  `json:"amount"`
+}
+
+func NormalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+func ValidRegion(region string) bool {
+	return regionPattern.MatchString(region)
+}
+
+// RegionIncludes dice si destination está dentro de region: es la misma región
+// o una subdivisión de ese país.
+func RegionIncludes(region string, destination string) bool {
+	return region == destination || strings.HasPrefix(destination, region+"-")
+}
+
+func NormalizeTaxClass(class string) string {
+	return strings.ToLower(strings.TrimSpace(class))
+}
+
+// ValidTaxClass acepta la clase vacía, que es la de los productos sin clase.
+func ValidTaxClass(class string) bool {
+	return taxClassPattern.MatchString(class)
+}
+
+func (rule *TaxRule) Validate() error {
+	if !ValidRegion(rule.Region) {
+		return errors.New("region must be an ISO country code, optionally followed by a subdivision (US-CA)")
+	}
+	if rule.CategoryId != "" && rule.TaxClass != "" {
+		return errors.New("a rule can target a category or a tax class, not both")
+	}
+	if !ValidTaxClass(rule.TaxClass) {
+		return errors.New("invalid tax_class")
+	}
+	if rule.RateBps < 0 || rule.RateBps > 10000 {
+		return errors.New("rate_bps must be between 0 and 10000")
+	}
+	return nil
+}
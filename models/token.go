@@ -0,0 +1,20 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+
+// RefreshToken se guarda por el hash del token, nunca en claro. Todos los
+// tokens que salen de una misma sesión comparten FamilyId, así un token
+// reutilizado tras rotar permite revocar la sesión completa.
+type RefreshToken struct {
+	Hash       string
+	UserId     string
+	FamilyId   string
+	Revoked    bool
+	Expires_at time.Time
+	Created_at time.Time
+}
@@ -1,7 +1,24 @@
 package models
 
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleSeller   Role = "seller"
+	RoleAdmin    Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleCustomer, RoleSeller, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
 	Id       string `json:"id"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Role     Role   `json:"role"`
 }
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrDuplicateSku     = errors.New("sku already in use")
+	ErrDuplicateVariant = errors.New("a variant with these options already exists")
+)
+
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariant struct {
+	Id         string            `json:"id"`
+	ProductId  string            `json:"product_id"`
+	Sku        string            `json:"sku"`
+	Price      *Money            `json:"price,omitempty"`
+	Stock      int               `json:"stock"`
+	Options    map[string]string `json:"options"`
+	Created_at time.Time         `json:"created_at"`
+	Updated_at time.Time         `json:"updated_at"`
+}
+
+// ProductDetail es lo que devuelve GET /product/{id}: el producto con sus
+// opciones, variantes, imágenes y el resumen de reseñas.
+type ProductDetail struct {
+	Products
+	ReviewSummary
+	Options  []*ProductOption  `json:"options"`
+	Variants []*ProductVariant `json:"variants"`
+	Images   []*ProductImage   `json:"images"`
+}
+
+// EffectivePrice es el precio propio de la variante o, si no tiene, el del
+// producto.
+func (v *ProductVariant) EffectivePrice(productPrice Money) Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return productPrice
+}
+
+func ValidateProductOptions(options []*ProductOption) error {
+	names := []string{}
+	for _, option := range options {
+		if option == nil {
+			return errors.New("options must not contain null entries")
+		}
+		if option.Name == "" || len(option.Values) == 0 {
+			return errors.New("every option needs a name and at least one value")
+		}
+		if slices.Contains(names, option.Name) {
+			return fmt.Errorf("option %q is repeated", option.Name)
+		}
+		names = append(names, option.Name)
+	}
+	return nil
+}
+
+// ValidateVariantOptions comprueba que la variante elija exactamente un valor
+// válido para cada opción del producto.
+func ValidateVariantOptions(options []*ProductOption, selected map[string]string) error {
+	if len(selected) != len(options) {
+		return errors.New("a variant must set a value for every product option")
+	}
+	for _, option := range options {
+		value, ok := selected[option.Name]
+		if !ok {
+			return fmt.Errorf("missing value for option %q", option.Name)
+		}
+		if !slices.Contains(option.Values, value) {
+			return fmt.Errorf("%q is not a valid value for option %q", value, option.Name)
+		}
+	}
+	return nil
+}
+
+func SameOptions(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if b[name] != value {
+			return false
+		}
+	}
+	return true
+}
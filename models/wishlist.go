@@ -0,0 +1,17 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrWishlistItemNotFound = errors.New("wishlist item not found")
+
+// WishlistItem guarda solo el id del producto; Product trae los datos
+// actuales y queda en nil con Deleted si el producto ya no existe.
+type WishlistItem struct {
+	ProductId string    `json:"product_id"`
+	Product   *Products `json:"product"`
+	Deleted   bool      `json:"deleted"`
+	Added_at  time.Time `json:"added_at"`
+}
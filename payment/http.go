@@ -0,0 +1,85 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// HTTPProvider habla con una pasarela remota que sigue la API de
+// MockProvider.ServeHTTP; sirve para usar el mock como stub local.
+type HTTPProvider struct {
+	baseUrl string
+	secret  string
+	client  *http.Client
+}
+
+func NewHTTPProvider(baseUrl string, secret string) *HTTPProvider {
+	return &HTTPProvider{
+		baseUrl: strings.TrimRight(baseUrl, "/"),
+		secret:  secret,
+		client:  &http.Client{},
+	}
+}
+
+func (p *HTTPProvider) Name() string {
+	return "http"
+}
+
+func (p *HTTPProvider) Authorize(ctx context.Context, request AuthorizeRequest) (*Result, error) {
+	return p.post(ctx, "/authorize", request)
+}
+
+func (p *HTTPProvider) Capture(ctx context.Context, reference string, amount models.Money) (*Result, error) {
+	return p.post(ctx, "/capture", amountRequest{Reference: reference, Amount: amount})
+}
+
+func (p *HTTPProvider) Refund(ctx context.Context, reference string, amount models.Money) (*Result, error) {
+	return p.post(ctx, "/refund", amountRequest{Reference: reference, Amount: amount})
+}
+
+func (p *HTTPProvider) VerifyWebhook(r *http.Request) (*WebhookEvent, error) {
+	return verifyWebhook(p.secret, r)
+}
+
+func (p *HTTPProvider) post(ctx context.Context, path string, body any) (*Result, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseUrl+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		var result Result
+		if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	case http.StatusPaymentRequired:
+		var failure errorResponse
+		if err := json.NewDecoder(response.Body).Decode(&failure); err != nil {
+			return nil, err
+		}
+		return failure.Result, ErrDeclined
+	case http.StatusNotFound:
+		return nil, ErrUnknownReference
+	default:
+		return nil, fmt.Errorf("payment provider responded %s", response.Status)
+	}
+}
@@ -0,0 +1,177 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/segmentio/ksuid"
+)
+
+// Fuentes de pago que el mock interpreta para simular respuestas de la
+// pasarela en pruebas.
+const (
+	MockDeclineSource = "tok_decline"
+	MockTimeoutSource = "tok_timeout"
+)
+
+type mockPayment struct {
+	amount models.Money
+	status Status
+}
+
+// MockProvider simula una pasarela dentro del proceso. También puede servirse
+// por HTTP con ServeHTTP para probar HTTPProvider contra un stub local.
+type MockProvider struct {
+	secret   string
+	mutex    sync.Mutex
+	payments map[string]*mockPayment
+
+	// Delay se aplica a cada llamada; si supera el deadline del contexto la
+	// llamada falla con el error del contexto.
+	Delay time.Duration
+}
+
+func NewMockProvider(secret string) *MockProvider {
+	return &MockProvider{
+		secret:   secret,
+		payments: make(map[string]*mockPayment),
+	}
+}
+
+func (m *MockProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockProvider) Authorize(ctx context.Context, request AuthorizeRequest) (*Result, error) {
+	delay := m.Delay
+	if request.Source == MockTimeoutSource {
+		delay = time.Hour
+	}
+	if err := m.wait(ctx, delay); err != nil {
+		return nil, err
+	}
+
+	reference := "mock_" + ksuid.New().String()
+	if request.Source == MockDeclineSource || request.Amount.Amount <= 0 {
+		m.store(reference, &mockPayment{amount: request.Amount, status: StatusDeclined})
+		return &Result{Reference: reference, Status: StatusDeclined, Message: "card declined"}, ErrDeclined
+	}
+
+	m.store(reference, &mockPayment{amount: request.Amount, status: StatusAuthorized})
+	return &Result{Reference: reference, Status: StatusAuthorized}, nil
+}
+
+func (m *MockProvider) Capture(ctx context.Context, reference string, amount models.Money) (*Result, error) {
+	return m.move(ctx, reference, StatusAuthorized, StatusCaptured)
+}
+
+func (m *MockProvider) Refund(ctx context.Context, reference string, amount models.Money) (*Result, error) {
+	return m.move(ctx, reference, StatusCaptured, StatusRefunded)
+}
+
+func (m *MockProvider) VerifyWebhook(r *http.Request) (*WebhookEvent, error) {
+	return verifyWebhook(m.secret, r)
+}
+
+func (m *MockProvider) move(ctx context.Context, reference string, from Status, to Status) (*Result, error) {
+	if err := m.wait(ctx, m.Delay); err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, ok := m.payments[reference]
+	if !ok {
+		return nil, ErrUnknownReference
+	}
+	if stored.status != from {
+		return &Result{Reference: reference, Status: stored.status, Message: "payment is " + string(stored.status)}, ErrDeclined
+	}
+	stored.status = to
+	return &Result{Reference: reference, Status: to}, nil
+}
+
+func (m *MockProvider) store(reference string, stored *mockPayment) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.payments[reference] = stored
+}
+
+func (m *MockProvider) wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type amountRequest struct {
+	Reference string       `json:"reference"`
+	Amount    models.Money `json:"amount"`
+}
+
+type errorResponse struct {
+	Error  string  `json:"error"`
+	Result *Result `json:"result,omitempty"`
+}
+
+// ServeHTTP expone el mock con la API que espera HTTPProvider:
+// POST /authorize, /capture y /refund.
+func (m *MockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var result *Result
+	var err error
+	switch r.URL.Path {
+	case "/authorize":
+		var request AuthorizeRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err = m.Authorize(r.Context(), request)
+	case "/capture", "/refund":
+		var request amountRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/capture" {
+			result, err = m.Capture(r.Context(), request.Reference, request.Amount)
+		} else {
+			result, err = m.Refund(r.Context(), request.Reference, request.Amount)
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch err {
+	case nil:
+		json.NewEncoder(w).Encode(result)
+	case ErrDeclined:
+		w.WriteHeader(http.StatusPaymentRequired)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error(), Result: result})
+	case ErrUnknownReference:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+	default:
+		w.WriteHeader(http.StatusGatewayTimeout)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+	}
+}
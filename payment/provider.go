@@ -0,0 +1,92 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+const (
+	DefaultCurrency = models.DefaultCurrency
+	SignatureHeader = "X-Payment-Signature"
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnknownReference = errors.New("unknown payment reference")
+)
+
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusRefunded   Status = "refunded"
+	StatusDeclined   Status = "declined"
+	StatusFailed     Status = "failed"
+)
+
+type AuthorizeRequest struct {
+	OrderId  string       `json:"order_id"`
+	Amount   models.Money `json:"amount"`
+	Currency string       `json:"currency"`
+	Source   string       `json:"source"`
+}
+
+type Result struct {
+	Reference string `json:"reference"`
+	Status    Status `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+type WebhookEvent struct {
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	Status    Status `json:"status"`
+}
+
+// Provider es lo que necesita la tienda de una pasarela de pagos. Authorize
+// devuelve ErrDeclined cuando la pasarela rechaza el cobro.
+type Provider interface {
+	Name() string
+	Authorize(ctx context.Context, request AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, reference string, amount models.Money) (*Result, error)
+	Refund(ctx context.Context, reference string, amount models.Money) (*Result, error)
+	VerifyWebhook(r *http.Request) (*WebhookEvent, error)
+}
+
+// Sign calcula la firma HMAC-SHA256 que acompaña a los webhooks.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyWebhook(secret string, r *http.Request) (*WebhookEvent, error) {
+	if secret == "" {
+		return nil, ErrInvalidSignature
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	expected := Sign(secret, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(SignatureHeader))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/cristiangar0398/ShopAPI/models"
 )
@@ -14,11 +15,86 @@ type Repository interface {
 	InsertUser(ctx context.Context, user *models.User) error
 	GetUserById(ctx context.Context, id string) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdateUserRole(ctx context.Context, id string, role models.Role) error
 	InsertProduct(ctc context.Context, product *models.Products) error
+	InsertProducts(ctx context.Context, products []*models.Products) error
 	GetProductById(ctx context.Context, id string) (*models.Products, error)
 	UpdateProduct(ctx context.Context, product *models.Products) error
 	DeleteProduct(ctx context.Context, id string, userID string) error
-	ListProducts(ctx context.Context, list uint64) ([]*models.Products, error)
+	ListProducts(ctx context.Context, filter models.ProductFilter) (*models.ProductPage, error)
+	EachProduct(ctx context.Context, filter models.ProductFilter, fn func(*models.Products) error) error
+	SearchProducts(ctx context.Context, search models.ProductSearch) (*models.ProductSearchPage, error)
+	InsertCategory(ctx context.Context, category *models.Category) error
+	GetCategoryById(ctx context.Context, id string) (*models.Category, error)
+	ListCategories(ctx context.Context) ([]*models.Category, error)
+	UpdateCategory(ctx context.Context, category *models.Category) error
+	DeleteCategory(ctx context.Context, id string) error
+	SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error
+	ListProductCategories(ctx context.Context, productID string) ([]*models.Category, error)
+	SetProductOptions(ctx context.Context, productID string, options []*models.ProductOption) error
+	ListProductOptions(ctx context.Context, productID string) ([]*models.ProductOption, error)
+	InsertVariant(ctx context.Context, variant *models.ProductVariant) error
+	GetVariantById(ctx context.Context, id string) (*models.ProductVariant, error)
+	ListVariants(ctx context.Context, productID string) ([]*models.ProductVariant, error)
+	UpdateVariant(ctx context.Context, variant *models.ProductVariant) error
+	DeleteVariant(ctx context.Context, productID string, id string) error
+	InsertProductImage(ctx context.Context, image *models.ProductImage) error
+	ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error)
+	DeleteProductImage(ctx context.Context, productID string, id string) error
+	ReorderProductImages(ctx context.Context, productID string, ids []string) error
+	InsertReview(ctx context.Context, review *models.Review) error
+	ListReviews(ctx context.Context, productID string, page uint64) ([]*models.Review, error)
+	GetReviewSummary(ctx context.Context, productID string) (*models.ReviewSummary, error)
+	HasDeliveredOrder(ctx context.Context, userID string, productID string) (bool, error)
+	GetCart(ctx context.Context, userID string) (*models.Cart, error)
+	AddCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error
+	UpdateCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error
+	DeleteCartItem(ctx context.Context, userID string, productID string, variantID string) error
+	SetCartCoupon(ctx context.Context, userID string, code string) error
+	InsertCoupon(ctx context.Context, coupon *models.Coupon) error
+	GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error)
+	ListCoupons(ctx context.Context) ([]*models.Coupon, error)
+	UpdateCoupon(ctx context.Context, coupon *models.Coupon) error
+	DeleteCoupon(ctx context.Context, code string) error
+	CountCouponUses(ctx context.Context, code string, userID string) (int, error)
+	InsertTaxRule(ctx context.Context, rule *models.TaxRule) error
+	GetTaxRuleById(ctx context.Context, id string) (*models.TaxRule, error)
+	ListTaxRules(ctx context.Context) ([]*models.TaxRule, error)
+	UpdateTaxRule(ctx context.Context, rule *models.TaxRule) error
+	DeleteTaxRule(ctx context.Context, id string) error
+	InsertShippingMethod(ctx context.Context, method *models.ShippingMethod) error
+	GetShippingMethodById(ctx context.Context, id string) (*models.ShippingMethod, error)
+	ListShippingMethods(ctx context.Context) ([]*models.ShippingMethod, error)
+	UpdateShippingMethod(ctx context.Context, method *models.ShippingMethod) error
+	DeleteShippingMethod(ctx context.Context, id string) error
+	InsertAddress(ctx context.Context, address *models.Address) error
+	GetAddressById(ctx context.Context, userID string, id string) (*models.Address, error)
+	ListAddresses(ctx context.Context, userID string) ([]*models.Address, error)
+	UpdateAddress(ctx context.Context, address *models.Address) error
+	DeleteAddress(ctx context.Context, userID string, id string) error
+	ListWishlist(ctx context.Context, userID string) ([]*models.WishlistItem, error)
+	AddWishlistItem(ctx context.Context, userID string, productID string) error
+	DeleteWishlistItem(ctx context.Context, userID string, productID string) error
+	ClearWishlist(ctx context.Context, userID string) error
+	InsertOrder(ctx context.Context, order *models.Order) error
+	GetOrderById(ctx context.Context, id string) (*models.Order, error)
+	ListOrders(ctx context.Context, userID string) ([]*models.Order, error)
+	UpdateOrderStatus(ctx context.Context, id string, from models.OrderStatus, to models.OrderStatus) error
+	GetInventory(ctx context.Context, productID string) (*models.Inventory, error)
+	ListInventories(ctx context.Context, productIDs []string) ([]*models.Inventory, error)
+	GetCatalogVersion(ctx context.Context) (*models.CatalogVersion, error)
+	SetInventory(ctx context.Context, productID string, stock *int) error
+	ExpireReservations(ctx context.Context, now time.Time) (int, error)
+	InsertPayment(ctx context.Context, payment *models.Payment) error
+	UpdatePayment(ctx context.Context, payment *models.Payment) error
+	GetPaymentByReference(ctx context.Context, reference string) (*models.Payment, error)
+	ListPayments(ctx context.Context, orderID string) ([]*models.Payment, error)
+	InsertRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, hash string) error
+	RevokeRefreshTokens(ctx context.Context, userID string, familyID string) error
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
 	Close() error
 }
 
@@ -34,6 +110,10 @@ func InsertProduct(ctx context.Context, post *models.Products) error {
 	return implementation.InsertProduct(ctx, post)
 }
 
+func InsertProducts(ctx context.Context, products []*models.Products) error {
+	return implementation.InsertProducts(ctx, products)
+}
+
 func GetUserById(ctx context.Context, id string) (*models.User, error) {
 	return implementation.GetUserById(ctx, id)
 }
@@ -46,6 +126,10 @@ func GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	return implementation.GetUserByEmail(ctx, email)
 }
 
+func UpdateUserRole(ctx context.Context, id string, role models.Role) error {
+	return implementation.UpdateUserRole(ctx, id, role)
+}
+
 func UpdateProduct(ctx context.Context, post *models.Products) error {
 	return implementation.UpdateProduct(ctx, post)
 }
@@ -54,8 +138,300 @@ func DeleteProduct(ctx context.Context, id string, userID string) error {
 	return implementation.DeleteProduct(ctx, id, userID)
 }
 
-func ListProducts(ctx context.Context, list uint64) ([]*models.Products, error) {
-	return implementation.ListProducts(ctx, list)
+func ListProducts(ctx context.Context, filter models.ProductFilter) (*models.ProductPage, error) {
+	return implementation.ListProducts(ctx, filter)
+}
+
+func EachProduct(ctx context.Context, filter models.ProductFilter, fn func(*models.Products) error) error {
+	return implementation.EachProduct(ctx, filter, fn)
+}
+
+func SearchProducts(ctx context.Context, search models.ProductSearch) (*models.ProductSearchPage, error) {
+	return implementation.SearchProducts(ctx, search)
+}
+
+func InsertCategory(ctx context.Context, category *models.Category) error {
+	return implementation.InsertCategory(ctx, category)
+}
+
+func GetCategoryById(ctx context.Context, id string) (*models.Category, error) {
+	return implementation.GetCategoryById(ctx, id)
+}
+
+func ListCategories(ctx context.Context) ([]*models.Category, error) {
+	return implementation.ListCategories(ctx)
+}
+
+func UpdateCategory(ctx context.Context, category *models.Category) error {
+	return implementation.UpdateCategory(ctx, category)
+}
+
+func DeleteCategory(ctx context.Context, id string) error {
+	return implementation.DeleteCategory(ctx, id)
+}
+
+func SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error {
+	return implementation.SetProductCategories(ctx, productID, categoryIDs)
+}
+
+func ListProductCategories(ctx context.Context, productID string) ([]*models.Category, error) {
+	return implementation.ListProductCategories(ctx, productID)
+}
+
+func SetProductOptions(ctx context.Context, productID string, options []*models.ProductOption) error {
+	return implementation.SetProductOptions(ctx, productID, options)
+}
+
+func ListProductOptions(ctx context.Context, productID string) ([]*models.ProductOption, error) {
+	return implementation.ListProductOptions(ctx, productID)
+}
+
+func InsertVariant(ctx context.Context, variant *models.ProductVariant) error {
+	return implementation.InsertVariant(ctx, variant)
+}
+
+func GetVariantById(ctx context.Context, id string) (*models.ProductVariant, error) {
+	return implementation.GetVariantById(ctx, id)
+}
+
+func ListVariants(ctx context.Context, productID string) ([]*models.ProductVariant, error) {
+	return implementation.ListVariants(ctx, productID)
+}
+
+func UpdateVariant(ctx context.Context, variant *models.ProductVariant) error {
+	return implementation.UpdateVariant(ctx, variant)
+}
+
+func DeleteVariant(ctx context.Context, productID string, id string) error {
+	return implementation.DeleteVariant(ctx, productID, id)
+}
+
+func InsertProductImage(ctx context.Context, image *models.ProductImage) error {
+	return implementation.InsertProductImage(ctx, image)
+}
+
+func ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error) {
+	return implementation.ListProductImages(ctx, productID)
+}
+
+func DeleteProductImage(ctx context.Context, productID string, id string) error {
+	return implementation.DeleteProductImage(ctx, productID, id)
+}
+
+func ReorderProductImages(ctx context.Context, productID string, ids []string) error {
+	return implementation.ReorderProductImages(ctx, productID, ids)
+}
+
+func InsertReview(ctx context.Context, review *models.Review) error {
+	return implementation.InsertReview(ctx, review)
+}
+
+func ListReviews(ctx context.Context, productID string, page uint64) ([]*models.Review, error) {
+	return implementation.ListReviews(ctx, productID, page)
+}
+
+func GetReviewSummary(ctx context.Context, productID string) (*models.ReviewSummary, error) {
+	return implementation.GetReviewSummary(ctx, productID)
+}
+
+func HasDeliveredOrder(ctx context.Context, userID string, productID string) (bool, error) {
+	return implementation.HasDeliveredOrder(ctx, userID, productID)
+}
+
+func GetCart(ctx context.Context, userID string) (*models.Cart, error) {
+	return implementation.GetCart(ctx, userID)
+}
+
+func AddCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error {
+	return implementation.AddCartItem(ctx, userID, productID, variantID, quantity)
+}
+
+func UpdateCartItem(ctx context.Context, userID string, productID string, variantID string, quantity int) error {
+	return implementation.UpdateCartItem(ctx, userID, productID, variantID, quantity)
+}
+
+func DeleteCartItem(ctx context.Context, userID string, productID string, variantID string) error {
+	return implementation.DeleteCartItem(ctx, userID, productID, variantID)
+}
+
+func SetCartCoupon(ctx context.Context, userID string, code string) error {
+	return implementation.SetCartCoupon(ctx, userID, code)
+}
+
+func InsertCoupon(ctx context.Context, coupon *models.Coupon) error {
+	return implementation.InsertCoupon(ctx, coupon)
+}
+
+func GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	return implementation.GetCouponByCode(ctx, code)
+}
+
+func ListCoupons(ctx context.Context) ([]*models.Coupon, error) {
+	return implementation.ListCoupons(ctx)
+}
+
+func UpdateCoupon(ctx context.Context, coupon *models.Coupon) error {
+	return implementation.UpdateCoupon(ctx, coupon)
+}
+
+func DeleteCoupon(ctx context.Context, code string) error {
+	return implementation.DeleteCoupon(ctx, code)
+}
+
+func CountCouponUses(ctx context.Context, code string, userID string) (int, error) {
+	return implementation.CountCouponUses(ctx, code, userID)
+}
+
+func InsertTaxRule(ctx context.Context, rule *models.TaxRule) error {
+	return implementation.InsertTaxRule(ctx, rule)
+}
+
+func GetTaxRuleById(ctx context.Context, id string) (*models.TaxRule, error) {
+	return implementation.GetTaxRuleById(ctx, id)
+}
+
+func ListTaxRules(ctx context.Context) ([]*models.TaxRule, error) {
+	return implementation.ListTaxRules(ctx)
+}
+
+func UpdateTaxRule(ctx context.Context, rule *models.TaxRule) error {
+	return implementation.UpdateTaxRule(ctx, rule)
+}
+
+func DeleteTaxRule(ctx context.Context, id string) error {
+	return implementation.DeleteTaxRule(ctx, id)
+}
+
+func InsertShippingMethod(ctx context.Context, method *models.ShippingMethod) error {
+	return implementation.InsertShippingMethod(ctx, method)
+}
+
+func GetShippingMethodById(ctx context.Context, id string) (*models.ShippingMethod, error) {
+	return implementation.GetShippingMethodById(ctx, id)
+}
+
+func ListShippingMethods(ctx context.Context) ([]*models.ShippingMethod, error) {
+	return implementation.ListShippingMethods(ctx)
+}
+
+func UpdateShippingMethod(ctx context.Context, method *models.ShippingMethod) error {
+	return implementation.UpdateShippingMethod(ctx, method)
+}
+
+func DeleteShippingMethod(ctx context.Context, id string) error {
+	return implementation.DeleteShippingMethod(ctx, id)
+}
+
+func InsertAddress(ctx context.Context, address *models.Address) error {
+	return implementation.InsertAddress(ctx, address)
+}
+
+func GetAddressById(ctx context.Context, userID string, id string) (*models.Address, error) {
+	return implementation.GetAddressById(ctx, userID, id)
+}
+
+func ListAddresses(ctx context.Context, userID string) ([]*models.Address, error) {
+	return implementation.ListAddresses(ctx, userID)
+}
+
+func UpdateAddress(ctx context.Context, address *models.Address) error {
+	return implementation.UpdateAddress(ctx, address)
+}
+
+func DeleteAddress(ctx context.Context, userID string, id string) error {
+	return implementation.DeleteAddress(ctx, userID, id)
+}
+
+func ListWishlist(ctx context.Context, userID string) ([]*models.WishlistItem, error) {
+	return implementation.ListWishlist(ctx, userID)
+}
+
+func AddWishlistItem(ctx context.Context, userID string, productID string) error {
+	return implementation.AddWishlistItem(ctx, userID, productID)
+}
+
+func DeleteWishlistItem(ctx context.Context, userID string, productID string) error {
+	return implementation.DeleteWishlistItem(ctx, userID, productID)
+}
+
+func ClearWishlist(ctx context.Context, userID string) error {
+	return implementation.ClearWishlist(ctx, userID)
+}
+
+func InsertOrder(ctx context.Context, order *models.Order) error {
+	return implementation.InsertOrder(ctx, order)
+}
+
+func GetOrderById(ctx context.Context, id string) (*models.Order, error) {
+	return implementation.GetOrderById(ctx, id)
+}
+
+func ListOrders(ctx context.Context, userID string) ([]*models.Order, error) {
+	return implementation.ListOrders(ctx, userID)
+}
+
+func UpdateOrderStatus(ctx context.Context, id string, from models.OrderStatus, to models.OrderStatus) error {
+	return implementation.UpdateOrderStatus(ctx, id, from, to)
+}
+
+func GetInventory(ctx context.Context, productID string) (*models.Inventory, error) {
+	return implementation.GetInventory(ctx, productID)
+}
+
+func ListInventories(ctx context.Context, productIDs []string) ([]*models.Inventory, error) {
+	return implementation.ListInventories(ctx, productIDs)
+}
+
+func GetCatalogVersion(ctx context.Context) (*models.CatalogVersion, error) {
+	return implementation.GetCatalogVersion(ctx)
+}
+
+func SetInventory(ctx context.Context, productID string, stock *int) error {
+	return implementation.SetInventory(ctx, productID, stock)
+}
+
+func ExpireReservations(ctx context.Context, now time.Time) (int, error) {
+	return implementation.ExpireReservations(ctx, now)
+}
+
+func InsertPayment(ctx context.Context, payment *models.Payment) error {
+	return implementation.InsertPayment(ctx, payment)
+}
+
+func UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	return implementation.UpdatePayment(ctx, payment)
+}
+
+func GetPaymentByReference(ctx context.Context, reference string) (*models.Payment, error) {
+	return implementation.GetPaymentByReference(ctx, reference)
+}
+
+func ListPayments(ctx context.Context, orderID string) ([]*models.Payment, error) {
+	return implementation.ListPayments(ctx, orderID)
+}
+
+func InsertRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return implementation.InsertRefreshToken(ctx, token)
+}
+
+func GetRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	return implementation.GetRefreshToken(ctx, hash)
+}
+
+func RevokeRefreshToken(ctx context.Context, hash string) error {
+	return implementation.RevokeRefreshToken(ctx, hash)
+}
+
+func RevokeRefreshTokens(ctx context.Context, userID string, familyID string) error {
+	return implementation.RevokeRefreshTokens(ctx, userID, familyID)
+}
+
+func RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return implementation.RevokeToken(ctx, jti, expiresAt)
+}
+
+func IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return implementation.IsTokenRevoked(ctx, jti)
 }
 
 func Close() error {
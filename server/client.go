@@ -0,0 +1,78 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait           = 10 * time.Second
+	pongWait            = 60 * time.Second
+	pingPeriod          = (pongWait * 9) / 10
+	maxMessageSize      = 512
+	sendBufferSize      = 256
+	broadcastBufferSize = 256
+)
+
+type Client struct {
+	hub    *Hub
+	socket *websocket.Conn
+	send   chan []byte
+}
+
+func NewClient(hub *Hub, socket *websocket.Conn) *Client {
+	return &Client{
+		hub:    hub,
+		socket: socket,
+		send:   make(chan []byte, sendBufferSize),
+	}
+}
+
+// Read solo existe para atender los pong y detectar el cierre de la conexión;
+// los clientes no envían mensajes al servidor.
+func (c *Client) Read() {
+	defer func() {
+		c.hub.unregister <- c
+		c.socket.Close()
+	}()
+
+	c.socket.SetReadLimit(maxMessageSize)
+	c.socket.SetReadDeadline(time.Now().Add(pongWait))
+	c.socket.SetPongHandler(func(string) error {
+		return c.socket.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.socket.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) Write() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.socket.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			c.socket.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.socket.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.socket.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.socket.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.socket.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+type Hub struct {
+	clients    map[*Client]bool
+	register   chan *Client
+	unregister chan *Client
+	broadcast  chan []byte
+	upgrader   websocket.Upgrader
+}
+
+// NewHub acepta conexiones del mismo host y de los orígenes de allowedOrigins
+// ("https://tienda.com"); "*" acepta cualquiera.
+func NewHub(allowedOrigins []string) *Hub {
+	hub := &Hub{
+		clients:    make(map[*Client]bool),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan []byte, broadcastBufferSize),
+	}
+	hub.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkOrigin(allowedOrigins),
+	}
+	return hub
+}
+
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]bool)
+	for _, origin := range allowedOrigins {
+		allowed[strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")] = true
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		// Los clientes que no son navegadores no mandan Origin.
+		if origin == "" || allowed["*"] || allowed[strings.ToLower(origin)] {
+			return true
+		}
+		parsed, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(parsed.Host, r.Host)
+	}
+}
+
+// Run es el único dueño del mapa de clientes; registra, elimina y reparte
+// los mensajes. Un cliente cuyo buffer está lleno se desconecta.
+func (hub *Hub) Run() {
+	for {
+		select {
+		case client := <-hub.register:
+			hub.clients[client] = true
+		case client := <-hub.unregister:
+			hub.removeClient(client)
+		case message := <-hub.broadcast:
+			for client := range hub.clients {
+				select {
+				case client.send <- message:
+				default:
+					log.Printf("websocket: evicting slow client %s", client.socket.RemoteAddr())
+					hub.removeClient(client)
+				}
+			}
+		}
+	}
+}
+
+func (hub *Hub) removeClient(client *Client) {
+	if _, ok := hub.clients[client]; ok {
+		delete(hub.clients, client)
+		close(client.send)
+	}
+}
+
+func (hub *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	socket, err := hub.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	client := NewClient(hub, socket)
+	hub.register <- client
+
+	go client.Write()
+	go client.Read()
+}
+
+// Broadcast envía el mensaje a todos los clientes conectados sin bloquear al
+// handler que lo llama; si la cola del hub está llena el mensaje se descarta.
+func (hub *Hub) Broadcast(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	select {
+	case hub.broadcast <- data:
+	default:
+		log.Println("websocket: broadcast queue full, dropping message")
+	}
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cristiangar0398/ShopAPI/repository"
+)
+
+const reservationSweepInterval = time.Minute
+
+// expireReservations cancela periódicamente las órdenes que no se pagaron a
+// tiempo para devolver su stock.
+func expireReservations(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		expired, err := repository.ExpireReservations(context.Background(), now)
+		if err != nil {
+			log.Println("reservations:", err)
+			continue
+		}
+		if expired > 0 {
+			log.Printf("reservations: cancelled %d unpaid orders", expired)
+		}
+	}
+}
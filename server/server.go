@@ -6,30 +6,74 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/cristiangar0398/ShopAPI/currency"
 	"github.com/cristiangar0398/ShopAPI/database"
+	"github.com/cristiangar0398/ShopAPI/models"
+	"github.com/cristiangar0398/ShopAPI/payment"
 	"github.com/cristiangar0398/ShopAPI/repository"
+	"github.com/cristiangar0398/ShopAPI/storage"
+	"github.com/cristiangar0398/ShopAPI/tax"
 	"github.com/gorilla/mux"
 )
 
 type Config struct {
-	Port        string
-	JWTSecret   string
-	BatabaseUrl string
+	Port                 string
+	JWTSecret            string
+	BatabaseUrl          string
+	InMemory             bool
+	PaymentProviderUrl   string
+	PaymentWebhookSecret string
+	StaticDir            string
+	StaticUrl            string
+	PublicUrl            string
+	OrdersDisabled       bool
+	RatesFile            string
+	AllowedOrigins       []string
 }
 
 type Server interface {
 	Config() *Config
+	Hub() *Hub
+	Payments() payment.Provider
+	Blobs() storage.BlobStore
+	Rates() currency.RateProvider
+	Taxes() tax.Calculator
 }
 
 type Broker struct {
-	config *Config
-	router *mux.Router
+	config   *Config
+	router   *mux.Router
+	hub      *Hub
+	payments payment.Provider
+	blobs    storage.BlobStore
+	rates    currency.RateProvider
+	taxes    tax.Calculator
 }
 
 func (b *Broker) Config() *Config {
 	return b.config
 }
 
+func (b *Broker) Hub() *Hub {
+	return b.hub
+}
+
+func (b *Broker) Payments() payment.Provider {
+	return b.payments
+}
+
+func (b *Broker) Blobs() storage.BlobStore {
+	return b.blobs
+}
+
+func (b *Broker) Rates() currency.RateProvider {
+	return b.rates
+}
+
+func (b *Broker) Taxes() tax.Calculator {
+	return b.taxes
+}
+
 func NewServer(ctx context.Context, config *Config) (*Broker, error) {
 	if config.Port == "" {
 		return nil, errors.New("port is required")
@@ -39,15 +83,39 @@ func NewServer(ctx context.Context, config *Config) (*Broker, error) {
 		return nil, errors.New("Secret is required")
 	}
 
-	if config.BatabaseUrl == "" {
+	if config.BatabaseUrl == "" && !config.InMemory {
 		return nil, errors.New("Data Base is required")
 	}
 
 	broker := &Broker{
 		config: config,
 		router: mux.NewRouter(),
+		hub:    NewHub(config.AllowedOrigins),
+	}
+
+	if config.PaymentProviderUrl != "" {
+		broker.payments = payment.NewHTTPProvider(config.PaymentProviderUrl, config.PaymentWebhookSecret)
+	} else {
+		broker.payments = payment.NewMockProvider(config.PaymentWebhookSecret)
 	}
 
+	if config.StaticDir != "" {
+		broker.blobs = storage.NewLocalStore(config.StaticDir, config.StaticUrl)
+	}
+
+	// Con un archivo de tasas estas quedan fijas; sin él las carga un admin.
+	if config.RatesFile != "" {
+		rates, err := currency.NewStaticProvider(config.RatesFile)
+		if err != nil {
+			return nil, err
+		}
+		broker.rates = rates
+	} else {
+		broker.rates = currency.NewMemoryProvider(models.DefaultCurrency)
+	}
+
+	broker.taxes = tax.NewRuleCalculator(repository.ListTaxRules)
+
 	return broker, nil
 }
 
@@ -55,12 +123,14 @@ func (b *Broker) Start(bainder func(s Server, r *mux.Router)) {
 	b.router = mux.NewRouter()
 	bainder(b, b.router)
 
-	repo, err := database.NewPostgresRepository(b.config.BatabaseUrl)
+	repo, err := newRepository(b.config)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	go b.hub.Run()
 	repository.SetRepository(repo)
+	go expireReservations(reservationSweepInterval)
 	port := b.Config().Port
 
 	log.Println(">>> >>> >>> 🚀 El servidor está despegando en el puerto", port, ">>> >>> >>>")
@@ -69,13 +139,32 @@ func (b *Broker) Start(bainder func(s Server, r *mux.Router)) {
 	}
 }
 
+func newRepository(config *Config) (repository.Repository, error) {
+	if config.InMemory {
+		log.Println(">>> >>> >>> 🧪 Usando el repositorio en memoria >>> >>> >>>")
+		return database.NewMemoryRepository(), nil
+	}
+	return database.NewPostgresRepository(config.BatabaseUrl)
+}
+
 func (b *Broker) StartStaticFileServer(staticPort string, staticDir string) {
 	fs := http.FileServer(http.Dir(staticDir))
 	staticRouter := mux.NewRouter()
 	staticRouter.PathPrefix("/").Handler(http.StripPrefix("/", fs))
 
-	log.Printf(">>> >>> >>> 📂 Servidor de archivos estáticos iniciado en el puerto %s", staticPort, ">>> >>> >>>")
+	log.Printf(">>> >>> >>> 📂 Servidor de archivos estáticos iniciado en el puerto %s >>> >>> >>>", staticPort)
 	if err := http.ListenAndServe(staticPort, staticRouter); err != nil {
 		log.Fatal("ListenAndServe static file server", err)
 	}
 }
+
+// StartPaymentStub sirve una pasarela simulada por HTTP para apuntar
+// PaymentProviderUrl a ella en desarrollo local.
+func (b *Broker) StartPaymentStub(stubPort string) {
+	stub := payment.NewMockProvider(b.config.PaymentWebhookSecret)
+
+	log.Printf(">>> >>> >>> 💳 Pasarela de pagos simulada iniciada en el puerto %s >>> >>> >>>", stubPort)
+	if err := http.ListenAndServe(stubPort, stub); err != nil {
+		log.Fatal("ListenAndServe payment stub", err)
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore escribe los archivos en un directorio del disco, normalmente el
+// STATIC_DIR que ya sirve StartStaticFileServer.
+type LocalStore struct {
+	dir     string
+	baseUrl string
+}
+
+func NewLocalStore(dir string, baseUrl string) *LocalStore {
+	return &LocalStore{dir: dir, baseUrl: strings.TrimSuffix(baseUrl, "/")}
+}
+
+func (store *LocalStore) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	target, err := store.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	// Se escribe a un temporal y se renombra para no servir archivos a medias.
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+	return store.baseUrl + "/" + key, nil
+}
+
+func (store *LocalStore) Delete(ctx context.Context, key string) error {
+	target, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (store *LocalStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean != "/"+key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(store.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore guarda archivos bajo una clave con "/" como separador y devuelve
+// la URL pública con la que se sirven.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
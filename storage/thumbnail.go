@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"image"
+	"image/color"
+)
+
+// Thumbnail reduce la imagen para que quepa en un cuadrado de size píxeles,
+// promediando los píxeles de origen que caen en cada píxel de destino. Las
+// imágenes que ya son más chicas se devuelven tal cual.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+
+	dstWidth, dstHeight := size, size
+	if width > height {
+		dstHeight = max(height*size/width, 1)
+	} else {
+		dstWidth = max(width*size/height, 1)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(bounds.Min.Y+(y+1)*height/dstHeight, y0+1)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(bounds.Min.X+(x+1)*width/dstWidth, x0+1)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					count++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+	return dst
+}
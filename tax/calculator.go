@@ -0,0 +1,120 @@
+package tax
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+// Line es un item a gravar. CategoryIds incluye las categorías del producto y
+// todos sus ancestros; Amount es la base imponible, ya descontado el cupón.
+type Line struct {
+	ProductId   string
+	TaxClass    string
+	CategoryIds []string
+	Amount      models.Money
+}
+
+type Request struct {
+	Region string
+	Lines  []Line
+}
+
+// Calculator calcula el impuesto de cada línea. El resultado tiene el mismo
+// orden que Request.Lines y un nil en las líneas sin impuesto.
+type Calculator interface {
+	Calculate(ctx context.Context, request *Request) ([]*models.TaxLine, error)
+}
+
+// RuleCalculator es el calculador por defecto; lee las reglas en cada cálculo
+// para que los cambios de un admin apliquen de inmediato.
+type RuleCalculator struct {
+	rules func(ctx context.Context) ([]*models.TaxRule, error)
+}
+
+func NewRuleCalculator(rules func(ctx context.Context) ([]*models.TaxRule, error)) *RuleCalculator {
+	return &RuleCalculator{rules: rules}
+}
+
+func (c *RuleCalculator) Calculate(ctx context.Context, request *Request) ([]*models.TaxLine, error) {
+	if request.Region == "" {
+		return make([]*models.TaxLine, len(request.Lines)), nil
+	}
+	rules, err := c.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Compute(rules, request), nil
+}
+
+// Compute aplica a cada línea la regla más específica que le corresponde. Una
+// regla de subdivisión gana a la del país; dentro de la misma región gana la
+// de clase de impuesto, después la de categoría y por último la general. Las
+// reglas no se acumulan.
+func Compute(rules []*models.TaxRule, request *Request) []*models.TaxLine {
+	lines := make([]*models.TaxLine, len(request.Lines))
+	for i, line := range request.Lines {
+		var best *models.TaxRule
+		bestRank := 0
+		for _, rule := range rules {
+			rank := matchRank(rule, request.Region, line)
+			if rank == 0 {
+				continue
+			}
+			if rank > bestRank || (rank == bestRank && rule.Id < best.Id) {
+				best, bestRank = rule, rank
+			}
+		}
+		if best != nil {
+			lines[i] = taxLine(best, line.Amount)
+		}
+	}
+	return lines
+}
+
+// matchRank devuelve 0 si la regla no aplica a la línea y si aplica un número
+// mayor cuanto más específica es.
+func matchRank(rule *models.TaxRule, region string, line Line) int {
+	rank := 0
+	switch {
+	case rule.Region == region:
+		rank = 10
+	case strings.HasPrefix(region, rule.Region+"-"):
+		rank = 5
+	default:
+		return 0
+	}
+	switch {
+	case rule.TaxClass != "":
+		if rule.TaxClass != line.TaxClass {
+			return 0
+		}
+		rank += 3
+	case rule.CategoryId != "":
+		if !slices.Contains(line.CategoryIds, rule.CategoryId) {
+			return 0
+		}
+		rank += 2
+	default:
+		rank++
+	}
+	return rank
+}
+
+// taxLine calcula el impuesto de la base. Si la regla es inclusiva el impuesto
+// se extrae del monto en lugar de sumarse.
+func taxLine(rule *models.TaxRule, amount models.Money) *models.TaxLine {
+	bps := int64(rule.RateBps)
+	tax := amount.Scale(bps, 10000)
+	if rule.Inclusive {
+		tax = amount.Scale(bps, 10000+bps)
+	}
+	return &models.TaxLine{
+		RuleId:    rule.Id,
+		RateBps:   rule.RateBps,
+		Inclusive: rule.Inclusive,
+		Amount:    tax,
+	}
+}
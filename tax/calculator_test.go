@@ -0,0 +1,92 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cristiangar0398/ShopAPI/models"
+)
+
+func TestCompute(t *testing.T) {
+	rules := []*models.TaxRule{
+		{Id: "us-ca", Region: "US-CA", RateBps: 725},
+		{Id: "us-ca-food", Region: "US-CA", TaxClass: "food", RateBps: 0},
+		{Id: "us", Region: "US", RateBps: 500},
+		{Id: "us-books", Region: "US", CategoryId: "books", RateBps: 100},
+		{Id: "us-digital", Region: "US", TaxClass: "digital", RateBps: 300},
+		{Id: "es-b", Region: "ES", RateBps: 2100, Inclusive: true},
+		{Id: "es-a", Region: "ES", RateBps: 1000, Inclusive: true},
+		{Id: "es-food", Region: "ES", TaxClass: "food", RateBps: 1000},
+	}
+	usd := func(amount int64) models.Money { return models.NewMoney(amount, "USD") }
+
+	tests := []struct {
+		name     string
+		region   string
+		line     Line
+		wantRule string
+		want     int64
+	}{
+		{"subdivision general", "US-CA", Line{Amount: usd(10000)}, "us-ca", 725},
+		{"subdivision beats country tax class", "US-CA", Line{TaxClass: "digital", Amount: usd(10000)}, "us-ca", 725},
+		{"subdivision tax class", "US-CA", Line{TaxClass: "food", Amount: usd(10000)}, "us-ca-food", 0},
+		{"country general for other state", "US-NY", Line{Amount: usd(10000)}, "us", 500},
+		{"country category", "US-NY", Line{CategoryIds: []string{"fiction", "books"}, Amount: usd(10000)}, "us-books", 100},
+		{"tax class beats category", "US", Line{TaxClass: "digital", CategoryIds: []string{"books"}, Amount: usd(10000)}, "us-digital", 300},
+		{"rounds half away from zero", "US", Line{Amount: usd(10)}, "us", 1},
+		{"tie goes to the smallest id", "ES", Line{Amount: usd(1100)}, "es-a", 100},
+		{"inclusive extracts the tax", "ES", Line{TaxClass: "books", Amount: usd(1100)}, "es-a", 100},
+		{"exclusive in same region", "ES", Line{TaxClass: "food", Amount: usd(1100)}, "es-food", 110},
+		{"no rule for region", "FR", Line{Amount: usd(10000)}, "", 0},
+		{"country code is not a prefix match", "USA", Line{Amount: usd(10000)}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Compute(rules, &Request{Region: tt.region, Lines: []Line{tt.line}})
+			if len(lines) != 1 {
+				t.Fatalf("Compute returned %d lines, want 1", len(lines))
+			}
+			got := lines[0]
+			if tt.wantRule == "" {
+				if got != nil {
+					t.Errorf("Compute = %+v, want no tax", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("Compute = nil, want rule %s", tt.wantRule)
+			}
+			if got.RuleId != tt.wantRule || got.Amount != usd(tt.want) {
+				t.Errorf("Compute = rule %s amount %s, want rule %s amount %d", got.RuleId, got.Amount, tt.wantRule, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleCalculator(t *testing.T) {
+	loads := 0
+	calculator := NewRuleCalculator(func(ctx context.Context) ([]*models.TaxRule, error) {
+		loads++
+		return []*models.TaxRule{{Id: "r", Region: "US", RateBps: 1000}}, nil
+	})
+	request := &Request{Lines: []Line{{Amount: models.NewMoney(100, "USD")}, {Amount: models.NewMoney(200, "USD")}}}
+
+	lines, err := calculator.Calculate(context.Background(), request)
+	if err != nil || len(lines) != 2 || lines[0] != nil || lines[1] != nil || loads != 0 {
+		t.Fatalf("without region = %v, %v, loads %d; want two nil lines and no rule loads", lines, err, loads)
+	}
+
+	request.Region = "US"
+	lines, err = calculator.Calculate(context.Background(), request)
+	if err != nil || lines[0].Amount.Amount != 10 || lines[1].Amount.Amount != 20 {
+		t.Fatalf("with region = %v, %v", lines, err)
+	}
+
+	failing := NewRuleCalculator(func(ctx context.Context) ([]*models.TaxRule, error) {
+		return nil, errors.New("boom")
+	})
+	if _, err := failing.Calculate(context.Background(), request); err == nil {
+		t.Error("Calculate did not return the rules error")
+	}
+}